@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"slinky/internal/config"
+	"slinky/internal/web"
+)
+
+var (
+	useCache  bool
+	cachePath string
+)
+
+// addCacheFlags registers the flags that turn the result cache on for a
+// checking command.
+func addCacheFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&useCache, "cache", false, "reuse results from the persistent cache (default path "+web.DefaultCachePath+")")
+	cmd.Flags().StringVar(&cachePath, "cache-path", "", "cache file to use; implies --cache")
+}
+
+// openCache returns the cache selected by flags and .slinkignore, or nil when
+// caching is off.
+func openCache(cmd *cobra.Command, fileCfg config.Config) (*web.Cache, error) {
+	path := fileCfg.CachePath()
+	if cmd.Flags().Changed("cache") {
+		path = ""
+		if useCache {
+			path = fileCfg.CachePath()
+			if path == "" {
+				path = web.DefaultCachePath
+			}
+		}
+	}
+	if cachePath != "" {
+		path = cachePath
+	}
+	if path == "" {
+		return nil, nil
+	}
+	c, err := web.OpenCache(path)
+	if err != nil {
+		return nil, fmt.Errorf("cache %s: %w", path, err)
+	}
+	return c, nil
+}
+
+func init() {
+	var (
+		path      string
+		failed    bool
+		olderThan time.Duration
+		all       bool
+	)
+	load := func() (*web.Cache, config.Config, error) {
+		fileCfg, err := config.Load(".")
+		if err != nil {
+			return nil, fileCfg, err
+		}
+		p := path
+		if p == "" {
+			p = fileCfg.CachePath()
+		}
+		if p == "" {
+			p = web.DefaultCachePath
+		}
+		c, err := web.OpenCache(p)
+		if err != nil {
+			return nil, fileCfg, fmt.Errorf("cache %s: %w", p, err)
+		}
+		return c, fileCfg, nil
+	}
+
+	cacheCmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Inspect and prune the persistent result cache",
+	}
+	cacheCmd.PersistentFlags().StringVar(&path, "path", "", "cache file (default from .slinkignore or "+web.DefaultCachePath+")")
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List cached results",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, fileCfg, err := load()
+			if err != nil {
+				return err
+			}
+			var wc web.Config
+			fileCfg.Apply(&wc)
+			now := time.Now()
+			tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+			fmt.Fprintln(tw, "RESULT\tSTATUS\tAGE\tFRESH\tVALIDATORS\tURL")
+			n := 0
+			for _, e := range c.Entries() {
+				if failed && e.OK {
+					continue
+				}
+				result := "ok"
+				if !e.OK {
+					result = "fail"
+				}
+				fresh := "no"
+				if e.Fresh(now, wc.CacheTTL, wc.CacheFailTTL) {
+					fresh = "yes"
+				}
+				validators := "-"
+				switch {
+				case e.ETag != "" && e.LastModified != "":
+					validators = "etag,last-modified"
+				case e.ETag != "":
+					validators = "etag"
+				case e.LastModified != "":
+					validators = "last-modified"
+				}
+				fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\t%s\n", result, e.Status, now.Sub(e.CheckedAt).Truncate(time.Second), fresh, validators, e.URL)
+				n++
+			}
+			if err := tw.Flush(); err != nil {
+				return err
+			}
+			fmt.Printf("%d entries in %s\n", n, c.Path())
+			return nil
+		},
+	}
+	listCmd.Flags().BoolVar(&failed, "failed", false, "only list failures")
+
+	pruneCmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove expired entries from the cache",
+		Long:  "Remove cache entries that are past their TTL, older than --older-than, or all of them with --all.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, fileCfg, err := load()
+			if err != nil {
+				return err
+			}
+			var wc web.Config
+			fileCfg.Apply(&wc)
+			now := time.Now()
+			removed := c.Prune(func(e web.CacheEntry) bool {
+				if all || (failed && !e.OK) {
+					return true
+				}
+				if olderThan > 0 {
+					return now.Sub(e.CheckedAt) > olderThan
+				}
+				return !e.Fresh(now, wc.CacheTTL, wc.CacheFailTTL)
+			})
+			if err := c.Save(); err != nil {
+				return err
+			}
+			fmt.Printf("Removed %d entries from %s\n", removed, c.Path())
+			return nil
+		},
+	}
+	pruneCmd.Flags().DurationVar(&olderThan, "older-than", 0, "remove entries checked longer ago than this (e.g. 72h)")
+	pruneCmd.Flags().BoolVar(&failed, "failed", false, "also remove all failures")
+	pruneCmd.Flags().BoolVar(&all, "all", false, "remove every entry")
+
+	cacheCmd.AddCommand(listCmd, pruneCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
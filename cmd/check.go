@@ -15,6 +15,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"slinky/internal/config"
 	"slinky/internal/fsurls"
 	"slinky/internal/report"
 	"slinky/internal/web"
@@ -22,13 +23,47 @@ import (
 
 // SerializableResult mirrors web.Result but omits the error field for JSON.
 type SerializableResult struct {
-	URL         string   `json:"url"`
-	OK          bool     `json:"ok"`
-	Status      int      `json:"status"`
-	ErrMsg      string   `json:"error"`
-	Method      string   `json:"method"`
-	ContentType string   `json:"contentType"`
-	Sources     []string `json:"sources"`
+	URL          string            `json:"url"`
+	RewrittenURL string            `json:"rewrittenUrl,omitempty"`
+	OK           bool              `json:"ok"`
+	Skipped      bool              `json:"skipped,omitempty"`
+	Severity     web.Severity      `json:"severity"`
+	CacheHit     bool              `json:"cacheHit,omitempty"`
+	Status       int               `json:"status"`
+	StatusRule   string            `json:"statusRule,omitempty"`
+	ErrMsg       string            `json:"error"`
+	ErrorKind    web.ErrorKind     `json:"errorKind,omitempty"`
+	Method       string            `json:"method"`
+	ContentType  string            `json:"contentType"`
+	Attempts     int               `json:"attempts,omitempty"`
+	Timing       *web.Timing       `json:"timing,omitempty"`
+	Redirects    []web.RedirectHop `json:"redirects,omitempty"`
+	Warnings     []string          `json:"warnings,omitempty"`
+	SuggestedURL string            `json:"suggestedUrl,omitempty"`
+	Sources      []fsurls.Source   `json:"sources"`
+}
+
+func newSerializableResult(r web.Result) SerializableResult {
+	return SerializableResult{
+		URL:          r.URL,
+		RewrittenURL: r.RewrittenURL,
+		OK:           r.OK,
+		Skipped:      r.Skipped,
+		Severity:     r.Severity,
+		CacheHit:     r.CacheHit,
+		Status:       r.Status,
+		StatusRule:   r.StatusRule,
+		ErrMsg:       r.ErrMsg,
+		ErrorKind:    r.ErrorKind,
+		Method:       r.Method,
+		ContentType:  r.ContentType,
+		Attempts:     r.Attempts,
+		Timing:       r.Timing,
+		Redirects:    r.Redirects,
+		Warnings:     r.Warnings,
+		SuggestedURL: r.SuggestedURL,
+		Sources:      r.Sources,
+	}
 }
 
 func init() {
@@ -37,131 +72,43 @@ func init() {
 		Short: "Scan for URLs and validate them (headless)",
 		Args:  cobra.ArbitraryArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// Parse targets: allow comma-separated chunks
-			var raw []string
-			for _, a := range args {
-				for part := range strings.SplitSeq(a, ",") {
-					p := strings.TrimSpace(part)
-					if p != "" {
-						raw = append(raw, toSlash(p))
-					}
-				}
-			}
-			if len(raw) == 0 {
-				raw = []string{"**/*"}
+			urlToFiles, displayRoot, err := collectTargets(args)
+			if err != nil {
+				return err
 			}
 
-			// Separate into globs (relative to ".") and concrete paths (dirs/files)
-			var globPatterns []string
-			type pathRoot struct {
-				path  string
-				isDir bool
-			}
-			var roots []pathRoot
-			for _, t := range raw {
-				if hasGlobMeta(t) {
-					globPatterns = append(globPatterns, t)
-					continue
-				}
-				if fi, err := os.Stat(t); err == nil {
-					roots = append(roots, pathRoot{path: t, isDir: fi.IsDir()})
-				} else {
-					// If stat fails, treat as glob pattern under "."
-					globPatterns = append(globPatterns, t)
-				}
+			if failOn != string(web.SeverityWarn) && failOn != string(web.SeverityError) {
+				return fmt.Errorf("--fail-on must be warn or error, got %q", failOn)
 			}
 
-			// Debug: show effective targets
-			if shouldDebug() {
-				fmt.Printf("::debug:: Roots: %s\n", strings.Join(func() []string {
-					var out []string
-					for _, r := range roots {
-						out = append(out, r.path)
-					}
-					return out
-				}(), ","))
-				fmt.Printf("::debug:: Glob patterns: %s\n", strings.Join(globPatterns, ","))
+			// Build config
+			timeout := time.Duration(timeoutSeconds) * time.Second
+			cfg := web.Config{MaxConcurrency: maxConcurrency, RequestTimeout: timeout, MaxRetries: maxRetries}
+			fileCfg, err := config.Load(".")
+			if err != nil {
+				return err
 			}
-
-			// Load ignore configurations once for all targets
-			gitIgnore := fsurls.LoadGitIgnore(".")
-			slPathIgnore, slURLPatterns := fsurls.LoadSlinkyIgnore(".")
-
-			// Aggregate URL->files across all targets
-			agg := make(map[string]map[string]struct{})
-			merge := func(res map[string][]string, prefix string, isDir bool) {
-				for u, files := range res {
-					set, ok := agg[u]
-					if !ok {
-						set = make(map[string]struct{})
-						agg[u] = set
-					}
-					for _, fp := range files {
-						var merged string
-						if prefix == "" {
-							merged = fp
-						} else if isDir {
-							merged = toSlash(filepath.Join(prefix, fp))
-						} else {
-							// File root: keep the concrete file path
-							merged = toSlash(prefix)
-						}
-						set[merged] = struct{}{}
-					}
-				}
+			fileCfg.Apply(&cfg)
+			if cmd.Flags().Changed("retries") {
+				cfg.MaxRetries = maxRetries
 			}
-
-			// 1) Collect for globs under current dir
-			if len(globPatterns) > 0 {
-				res, err := fsurls.CollectURLsWithIgnoreConfig(".", globPatterns, respectGitignore, gitIgnore, slPathIgnore, slURLPatterns)
-				if err != nil {
-					return err
-				}
-				merge(res, "", true)
+			if cmd.Flags().Changed("timeout") {
+				cfg.RequestTimeout = timeout
 			}
-
-			// 2) Collect for each concrete root
-			for _, r := range roots {
-				clean := toSlash(filepath.Clean(r.path))
-				if r.isDir {
-					res, err := fsurls.CollectURLsWithIgnoreConfig(r.path, []string{"**/*"}, respectGitignore, gitIgnore, slPathIgnore, slURLPatterns)
-					if err != nil {
-						return err
-					}
-					merge(res, clean, true)
-				} else {
-					res, err := fsurls.CollectURLsWithIgnoreConfig(r.path, nil, respectGitignore, gitIgnore, slPathIgnore, slURLPatterns)
-					if err != nil {
-						return err
-					}
-					merge(res, clean, false)
-				}
+			applyTimingFlags(cmd, &cfg)
+			if cmd.Flags().Changed("https-upgrade") {
+				cfg.HTTPSUpgrade = httpsUpgrade
 			}
-
-			// Convert aggregator to final map with sorted file lists
-			urlToFiles := make(map[string][]string, len(agg))
-			for u, set := range agg {
-				var files []string
-				for f := range set {
-					files = append(files, f)
-				}
-				sort.Strings(files)
-				urlToFiles[u] = files
+			if checkMX {
+				cfg.CheckMX = true
 			}
-
-			// Derive display root; we use "." when multiple roots to avoid confusion
-			displayRoot := "."
-			if len(roots) == 1 && len(globPatterns) == 0 {
-				displayRoot = roots[0].path
+			if strings.TrimSpace(dnsServer) != "" {
+				cfg.DNSServer = dnsServer
 			}
-			if shouldDebug() {
-				fmt.Printf("::debug:: Root: %s\n", displayRoot)
+			if cfg.Cache, err = openCache(cmd, fileCfg); err != nil {
+				return err
 			}
 
-			// Build config
-			timeout := time.Duration(timeoutSeconds) * time.Second
-			cfg := web.Config{MaxConcurrency: maxConcurrency, RequestTimeout: timeout}
-
 			// Prepare URL list
 			var urls []string
 			for u := range urlToFiles {
@@ -182,18 +129,27 @@ func init() {
 			results := make(chan web.Result, 256)
 			go web.CheckURLs(ctx, urls, urlToFiles, results, nil, cfg)
 
-			var total, okCount, failCount int
+			var total, okCount, failCount, skipCount, warnCount, cacheCount int
 			totalURLs := len(urls)
 			lastPctLogged := 0
 			var failures []SerializableResult
-			var failedResults []web.Result
+			var issueResults []web.Result
+			var timed []web.Result
 
 			for r := range results {
 				total++
-				if r.OK {
-					okCount++
-				} else {
+				if r.CacheHit {
+					cacheCount++
+				}
+				switch {
+				case r.Skipped:
+					skipCount++
+				case r.Severity == web.SeverityError:
 					failCount++
+				case r.Severity == web.SeverityWarn:
+					warnCount++
+				default:
+					okCount++
 				}
 				// Progress notices every 5%
 				if totalURLs > 0 {
@@ -207,25 +163,27 @@ func init() {
 				// These lines appear only when step debug logging is enabled via the
 				// repository/organization secret ACTIONS_STEP_DEBUG=true.
 				if shouldDebug() {
-					fmt.Printf("::debug:: Scanned URL: %s status=%d ok=%v err=%s sources=%d\n", r.URL, r.Status, r.OK, r.ErrMsg, len(r.Sources))
+					fmt.Printf("::debug:: Scanned URL: %s status=%d ok=%v kind=%s err=%s sources=%d\n", r.URL, r.Status, r.OK, r.ErrorKind, r.ErrMsg, len(r.Sources))
+				}
+				if jsonOut != "" && (r.Skipped || r.Severity != web.SeverityOK || r.StatusPolicyDecided()) {
+					failures = append(failures, newSerializableResult(r))
 				}
-				if jsonOut != "" && !r.OK {
-					failures = append(failures, SerializableResult{
-						URL:         r.URL,
-						OK:          r.OK,
-						Status:      r.Status,
-						ErrMsg:      r.ErrMsg,
-						Method:      r.Method,
-						ContentType: r.ContentType,
-						Sources:     r.Sources,
-					})
+				if r.Severity != web.SeverityOK {
+					issueResults = append(issueResults, r)
 				}
-				if !r.OK {
-					failedResults = append(failedResults, r)
+				if cfg.SlowThreshold > 0 && (r.Timing != nil || r.CacheHit) {
+					timed = append(timed, r)
 				}
 			}
 
-			// Write JSON if requested (failures only)
+			if cfg.Cache != nil {
+				if err := cfg.Cache.Save(); err != nil {
+					return err
+				}
+			}
+
+			// Write JSON if requested (failures, warnings and passes decided by a
+			// status policy)
 			if jsonOut != "" {
 				f, ferr := os.Create(jsonOut)
 				if ferr != nil {
@@ -252,9 +210,15 @@ func init() {
 				Processed:       total,
 				OK:              okCount,
 				Fail:            failCount,
+				Skipped:         skipCount,
+				Warn:            warnCount,
 				FilesScanned:    countFiles(urlToFiles),
 				JSONPath:        jsonOut,
 				RepoBlobBaseURL: base,
+				SlowThreshold:   cfg.SlowThreshold,
+			}
+			if cfg.SlowThreshold > 0 {
+				summary.Latency = report.HostLatencies(timed, cfg.SlowThreshold)
 			}
 
 			// Ensure we have a markdown file if needed for PR comment
@@ -262,12 +226,12 @@ func init() {
 			ghRepo, ghPR, ghToken, ghOK := detectGitHubPR()
 			var finalMDPath string
 			if strings.TrimSpace(mdPath) != "" {
-				if _, err := report.WriteMarkdown(mdPath, failedResults, summary); err != nil {
+				if _, err := report.WriteMarkdown(mdPath, issueResults, summary); err != nil {
 					return err
 				}
 				finalMDPath = mdPath
 			} else if ghOK {
-				p, err := report.WriteMarkdown("", failedResults, summary)
+				p, err := report.WriteMarkdown("", issueResults, summary)
 				if err != nil {
 					return err
 				}
@@ -290,10 +254,23 @@ func init() {
 				}
 			}
 
-			fmt.Printf("Checked %d URLs: %d OK, %d failed\n", total, okCount, failCount)
+			line := fmt.Sprintf("Checked %d URLs: %d OK, %d failed", total, okCount, failCount)
+			if skipCount > 0 {
+				line += fmt.Sprintf(", %d skipped", skipCount)
+			}
+			if warnCount > 0 {
+				line += fmt.Sprintf(", %d with warnings", warnCount)
+			}
+			if cacheCount > 0 {
+				line += fmt.Sprintf(" (%d from cache)", cacheCount)
+			}
+			fmt.Println(line)
 			if failOnFailures && failCount > 0 {
 				return fmt.Errorf("%d links failed", failCount)
 			}
+			if failOnFailures && failOn == string(web.SeverityWarn) && warnCount > 0 {
+				return fmt.Errorf("%d links have warnings", warnCount)
+			}
 			return nil
 		},
 	}
@@ -303,8 +280,15 @@ func init() {
 	checkCmd.Flags().StringVar(&mdOut, "md-out", "", "path to write Markdown report for PR comment")
 	checkCmd.Flags().StringVar(&repoBlobBase, "repo-blob-base", "", "override GitHub blob base URL (e.g. https://github.com/owner/repo/blob/<sha>)")
 	checkCmd.Flags().IntVar(&timeoutSeconds, "timeout", 10, "HTTP request timeout in seconds")
+	addTimingFlags(checkCmd)
+	checkCmd.Flags().IntVar(&maxRetries, "retries", 2, "retries for transient failures (timeouts, 429, 502-504)")
 	checkCmd.Flags().BoolVar(&failOnFailures, "fail-on-failures", true, "exit non-zero if any links fail")
+	checkCmd.Flags().StringVar(&failOn, "fail-on", "error", "lowest severity that fails the run: warn or error")
 	checkCmd.Flags().BoolVar(&respectGitignore, "respect-gitignore", true, "respect .gitignore while scanning (default true)")
+	checkCmd.Flags().BoolVar(&checkMX, "check-mx", false, "verify mailto: recipient domains have MX or A records")
+	checkCmd.Flags().BoolVar(&httpsUpgrade, "https-upgrade", false, "warn when an http:// link also works over HTTPS")
+	addCacheFlags(checkCmd)
+	checkCmd.Flags().StringVar(&dnsServer, "dns-server", "", "DNS server (host:port) for mailto: domain checks; defaults to the system resolver")
 
 	rootCmd.AddCommand(checkCmd)
 }
@@ -312,10 +296,45 @@ func init() {
 var (
 	timeoutSeconds   int
 	failOnFailures   bool
+	failOn           string
+	httpsUpgrade     bool
 	repoBlobBase     string
 	respectGitignore bool
+	checkMX          bool
+	dnsServer        string
 )
 
+var (
+	connectTimeout time.Duration
+	tlsTimeout     time.Duration
+	headerTimeout  time.Duration
+	slowThreshold  time.Duration
+)
+
+// addTimingFlags registers the per-phase timeout and slow-link flags.
+func addTimingFlags(cmd *cobra.Command) {
+	cmd.Flags().DurationVar(&connectTimeout, "connect-timeout", 2*time.Second, "timeout for establishing a connection")
+	cmd.Flags().DurationVar(&tlsTimeout, "tls-timeout", 5*time.Second, "timeout for the TLS handshake")
+	cmd.Flags().DurationVar(&headerTimeout, "header-timeout", 0, "timeout waiting for response headers (default: the request timeout)")
+	cmd.Flags().DurationVar(&slowThreshold, "slow-threshold", 0, "warn about links slower than this (e.g. 3s)")
+}
+
+// applyTimingFlags lets explicitly set timing flags override .slinkignore.
+func applyTimingFlags(cmd *cobra.Command, cfg *web.Config) {
+	if cmd.Flags().Changed("connect-timeout") {
+		cfg.ConnectTimeout = connectTimeout
+	}
+	if cmd.Flags().Changed("tls-timeout") {
+		cfg.TLSTimeout = tlsTimeout
+	}
+	if cmd.Flags().Changed("header-timeout") {
+		cfg.HeaderTimeout = headerTimeout
+	}
+	if cmd.Flags().Changed("slow-threshold") {
+		cfg.SlowThreshold = slowThreshold
+	}
+}
+
 func toSlash(p string) string {
 	p = strings.TrimSpace(p)
 	if p == "" {
@@ -332,11 +351,11 @@ func hasGlobMeta(s string) bool {
 	return strings.ContainsAny(s, "*?[")
 }
 
-func countFiles(urlToFiles map[string][]string) int {
+func countFiles(urlToFiles map[string][]fsurls.Source) int {
 	seen := make(map[string]struct{})
-	for _, files := range urlToFiles {
-		for _, f := range files {
-			seen[f] = struct{}{}
+	for _, srcs := range urlToFiles {
+		for _, src := range srcs {
+			seen[src.File] = struct{}{}
 		}
 	}
 	return len(seen)
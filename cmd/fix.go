@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"slinky/internal/config"
+	"slinky/internal/fix"
+	"slinky/internal/web"
+)
+
+func init() {
+	var (
+		dryRun    bool
+		redirects bool
+		upgrade   bool
+	)
+	fixCmd := &cobra.Command{
+		Use:   "fix [targets...]",
+		Short: "Rewrite permanently redirected and migrated URLs in place",
+		Args:  cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			urlToFiles, _, err := collectTargets(args)
+			if err != nil {
+				return err
+			}
+			fileCfg, err := config.Load(".")
+			if err != nil {
+				return err
+			}
+
+			// Configured migrations need no requests
+			replacements := make(map[string]string)
+			var toCheck []string
+			for u := range urlToFiles {
+				if m := web.ApplyRewrites(fileCfg.Migrations, u); m != u {
+					replacements[u] = m
+				} else {
+					toCheck = append(toCheck, u)
+				}
+			}
+			sort.Strings(toCheck)
+
+			if !cmd.Flags().Changed("https") {
+				upgrade = fileCfg.HTTPSUpgrade
+			}
+			if (redirects || upgrade) && len(toCheck) > 0 {
+				cfg := web.Config{MaxConcurrency: maxConcurrency, RequestTimeout: time.Duration(timeoutSeconds) * time.Second, MaxRetries: maxRetries}
+				fileCfg.Apply(&cfg)
+				cfg.HTTPSUpgrade = upgrade
+				if cfg.Cache, err = openCache(cmd, fileCfg); err != nil {
+					return err
+				}
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				results := make(chan web.Result, 256)
+				go web.CheckURLs(ctx, toCheck, nil, results, nil, cfg)
+				for r := range results {
+					// A suggestion for a rewritten URL does not apply to the original
+					if !r.OK || r.SuggestedURL == "" || r.RewrittenURL != "" {
+						continue
+					}
+					if r.ErrorKind == web.ErrorKindHTTPSUpgrade || redirects {
+						replacements[r.URL] = r.SuggestedURL
+					}
+				}
+				if cfg.Cache != nil {
+					if err := cfg.Cache.Save(); err != nil {
+						return err
+					}
+				}
+			}
+
+			// Group edits by file
+			edits := make(map[string][]fix.Edit)
+			for u, repl := range replacements {
+				for _, src := range urlToFiles[u] {
+					edits[src.File] = append(edits[src.File], fix.Edit{Start: src.StartOffset, End: src.EndOffset, Old: u, New: repl})
+				}
+			}
+			var files []string
+			for f := range edits {
+				files = append(files, f)
+			}
+			sort.Strings(files)
+
+			var fixedLinks, fixedFiles int
+			for _, f := range files {
+				before, err := os.ReadFile(f)
+				if err != nil {
+					return err
+				}
+				after, skipped := fix.Apply(before, edits[f])
+				for _, e := range skipped {
+					fmt.Fprintf(os.Stderr, "skipped %s at byte %d: span does not hold %s\n", f, e.Start, e.Old)
+				}
+				applied := len(edits[f]) - len(skipped)
+				if applied == 0 {
+					continue
+				}
+				fixedLinks += applied
+				fixedFiles++
+				if dryRun {
+					fmt.Print(fix.UnifiedDiff(f, before, after))
+					continue
+				}
+				info, err := os.Stat(f)
+				if err != nil {
+					return err
+				}
+				if err := os.WriteFile(f, after, info.Mode().Perm()); err != nil {
+					return err
+				}
+			}
+
+			verb := "Fixed"
+			if dryRun {
+				verb = "Would fix"
+			}
+			fmt.Printf("%s %d links in %d files\n", verb, fixedLinks, fixedFiles)
+			return nil
+		},
+	}
+
+	fixCmd.Flags().BoolVar(&dryRun, "dry-run", false, "print a unified diff instead of writing files")
+	fixCmd.Flags().BoolVar(&redirects, "redirects", true, "check URLs and replace permanent redirects with their target")
+	fixCmd.Flags().IntVar(&maxConcurrency, "concurrency", 16, "maximum concurrent requests")
+	fixCmd.Flags().IntVar(&timeoutSeconds, "timeout", 10, "HTTP request timeout in seconds")
+	fixCmd.Flags().IntVar(&maxRetries, "retries", 2, "retries for transient failures (timeouts, 429, 502-504)")
+	fixCmd.Flags().BoolVar(&upgrade, "https", false, "check http:// URLs and upgrade those that serve the same resource over HTTPS")
+	addCacheFlags(fixCmd)
+	fixCmd.Flags().BoolVar(&respectGitignore, "respect-gitignore", true, "respect .gitignore while scanning (default true)")
+	rootCmd.AddCommand(fixCmd)
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"slinky/internal/config"
+	"slinky/internal/web"
+)
+
+func init() {
+	rewritesCmd := &cobra.Command{
+		Use:   "rewrites [targets...]",
+		Short: "Print how configured rewrite rules map scanned URLs (dry run, no requests)",
+		Args:  cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fileCfg, err := config.Load(".")
+			if err != nil {
+				return err
+			}
+			if len(fileCfg.Rewrites) == 0 {
+				fmt.Println("No rewrite rules configured.")
+				return nil
+			}
+
+			urlToFiles, _, err := collectTargets(args)
+			if err != nil {
+				return err
+			}
+			var urls []string
+			for u := range urlToFiles {
+				urls = append(urls, u)
+			}
+			sort.Strings(urls)
+
+			changed := 0
+			for _, u := range urls {
+				target := web.ApplyRewrites(fileCfg.Rewrites, u)
+				if target == u {
+					continue
+				}
+				changed++
+				fmt.Printf("%s -> %s\n", u, target)
+			}
+			fmt.Printf("%d of %d URLs rewritten\n", changed, len(urls))
+			return nil
+		},
+	}
+
+	rewritesCmd.Flags().BoolVar(&respectGitignore, "respect-gitignore", true, "respect .gitignore while scanning (default true)")
+	rootCmd.AddCommand(rewritesCmd)
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"slinky/internal/config"
 	"slinky/internal/tui"
 	"slinky/internal/web"
 )
@@ -16,7 +17,22 @@ func init() {
 		Short: "Scan a directory/repo for URLs in files and validate them (TUI)",
 		Args:  cobra.ArbitraryArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cfg := web.Config{MaxConcurrency: maxConcurrency}
+			cfg := web.Config{MaxConcurrency: maxConcurrency, MaxRetries: maxRetries}
+			fileCfg, err := config.Load(".")
+			if err != nil {
+				return err
+			}
+			fileCfg.Apply(&cfg)
+			if cmd.Flags().Changed("retries") {
+				cfg.MaxRetries = maxRetries
+			}
+			applyTimingFlags(cmd, &cfg)
+			if cmd.Flags().Changed("https-upgrade") {
+				cfg.HTTPSUpgrade = httpsUpgrade
+			}
+			if cfg.Cache, err = openCache(cmd, fileCfg); err != nil {
+				return err
+			}
 			var gl []string
 			if len(args) > 0 {
 				for _, a := range args {
@@ -45,20 +61,31 @@ func init() {
 				}
 			}
 
-			return tui.Run(root, gl, cfg, jsonOut, mdOut, watchMode)
+			if err := tui.Run(root, gl, cfg, jsonOut, mdOut, watchMode); err != nil {
+				return err
+			}
+			if cfg.Cache != nil {
+				return cfg.Cache.Save()
+			}
+			return nil
 		},
 	}
 
 	runCmd.Flags().IntVar(&maxConcurrency, "concurrency", 16, "maximum concurrent requests")
+	runCmd.Flags().IntVar(&maxRetries, "retries", 2, "retries for transient failures (timeouts, 429, 502-504)")
 	runCmd.Flags().StringVar(&jsonOut, "json-out", "", "path to write full JSON results (array)")
 	runCmd.Flags().StringVar(&mdOut, "md-out", "", "path to write Markdown report for PR comment")
 	runCmd.Flags().StringVar(&repoBlobBase, "repo-blob-base", "", "override GitHub blob base URL (e.g. https://github.com/owner/repo/blob/<sha>)")
 	runCmd.Flags().BoolVar(&watchMode, "watch", false, "watch for file changes and automatically re-scan")
+	runCmd.Flags().BoolVar(&httpsUpgrade, "https-upgrade", false, "warn when an http:// link also works over HTTPS")
+	addTimingFlags(runCmd)
+	addCacheFlags(runCmd)
 	rootCmd.AddCommand(runCmd)
 }
 
 var (
 	maxConcurrency int
+	maxRetries     int
 	jsonOut        string
 	mdOut          string
 	watchMode      bool
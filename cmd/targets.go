@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"slinky/internal/fsurls"
+)
+
+// collectTargets parses check-style targets (files, directories, globs and
+// comma-separated chunks), scans them and returns URL -> sorted sources along
+// with the root to display in reports.
+func collectTargets(args []string) (map[string][]fsurls.Source, string, error) {
+	// Parse targets: allow comma-separated chunks
+	var raw []string
+	for _, a := range args {
+		for part := range strings.SplitSeq(a, ",") {
+			p := strings.TrimSpace(part)
+			if p != "" {
+				raw = append(raw, toSlash(p))
+			}
+		}
+	}
+	if len(raw) == 0 {
+		raw = []string{"**/*"}
+	}
+
+	// Separate into globs (relative to ".") and concrete paths (dirs/files)
+	var globPatterns []string
+	type pathRoot struct {
+		path  string
+		isDir bool
+	}
+	var roots []pathRoot
+	for _, t := range raw {
+		if hasGlobMeta(t) {
+			globPatterns = append(globPatterns, t)
+			continue
+		}
+		if fi, err := os.Stat(t); err == nil {
+			roots = append(roots, pathRoot{path: t, isDir: fi.IsDir()})
+		} else {
+			// If stat fails, treat as glob pattern under "."
+			globPatterns = append(globPatterns, t)
+		}
+	}
+
+	// Debug: show effective targets
+	if shouldDebug() {
+		fmt.Printf("::debug:: Roots: %s\n", strings.Join(func() []string {
+			var out []string
+			for _, r := range roots {
+				out = append(out, r.path)
+			}
+			return out
+		}(), ","))
+		fmt.Printf("::debug:: Glob patterns: %s\n", strings.Join(globPatterns, ","))
+	}
+
+	// Load ignore configurations once for all targets
+	gitIgnore := fsurls.LoadGitIgnore(".")
+	slPathIgnore, slURLPatterns := fsurls.LoadSlinkyIgnore(".")
+
+	// Aggregate URL->sources across all targets
+	agg := make(map[string]map[string]fsurls.Source)
+	merge := func(res map[string][]fsurls.Source, prefix string, isDir bool) {
+		for u, srcs := range res {
+			set, ok := agg[u]
+			if !ok {
+				set = make(map[string]fsurls.Source)
+				agg[u] = set
+			}
+			for _, src := range srcs {
+				if prefix != "" {
+					if isDir {
+						src.File = toSlash(filepath.Join(prefix, src.File))
+					} else {
+						// File root: keep the concrete file path
+						src.File = toSlash(prefix)
+					}
+				}
+				set[src.String()] = src
+			}
+		}
+	}
+
+	// 1) Collect for globs under current dir
+	if len(globPatterns) > 0 {
+		res, err := fsurls.CollectURLsWithIgnoreConfig(".", globPatterns, respectGitignore, gitIgnore, slPathIgnore, slURLPatterns)
+		if err != nil {
+			return nil, "", err
+		}
+		merge(res, "", true)
+	}
+
+	// 2) Collect for each concrete root
+	for _, r := range roots {
+		clean := toSlash(filepath.Clean(r.path))
+		if r.isDir {
+			res, err := fsurls.CollectURLsWithIgnoreConfig(r.path, []string{"**/*"}, respectGitignore, gitIgnore, slPathIgnore, slURLPatterns)
+			if err != nil {
+				return nil, "", err
+			}
+			merge(res, clean, true)
+		} else {
+			res, err := fsurls.CollectURLsWithIgnoreConfig(r.path, nil, respectGitignore, gitIgnore, slPathIgnore, slURLPatterns)
+			if err != nil {
+				return nil, "", err
+			}
+			merge(res, clean, false)
+		}
+	}
+
+	// Convert aggregator to final map with sorted source lists
+	urlToFiles := make(map[string][]fsurls.Source, len(agg))
+	for u, set := range agg {
+		var srcs []fsurls.Source
+		for _, src := range set {
+			srcs = append(srcs, src)
+		}
+		fsurls.SortSources(srcs)
+		urlToFiles[u] = srcs
+	}
+
+	// Derive display root; we use "." when multiple roots to avoid confusion
+	displayRoot := "."
+	if len(roots) == 1 && len(globPatterns) == 0 {
+		displayRoot = roots[0].path
+	}
+	if shouldDebug() {
+		fmt.Printf("::debug:: Root: %s\n", displayRoot)
+	}
+	return urlToFiles, displayRoot, nil
+}
@@ -47,6 +47,10 @@ else
   set -- "$@" --fail-on-failures=false
 fi
 
+if [ -n "${INPUT_FAIL_ON:-}" ]; then
+  set -- "$@" --fail-on "${INPUT_FAIL_ON}"
+fi
+
 if [ "${INPUT_RESPECT_GITIGNORE:-true}" = "true" ]; then
   set -- "$@" --respect-gitignore=true
 else
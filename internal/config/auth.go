@@ -0,0 +1,81 @@
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+
+	"slinky/internal/web"
+)
+
+// AuthConfig names the environment variables that hold credentials for hosts
+// matching Match, so secrets never live in .slinkignore. Headers and Cookies
+// map a header or cookie name to an environment variable.
+type AuthConfig struct {
+	Match       string            `json:"match"`
+	Headers     map[string]string `json:"headers" optional:"true"`
+	BearerEnv   string            `json:"bearerEnv" optional:"true"`
+	UsernameEnv string            `json:"usernameEnv" optional:"true"`
+	PasswordEnv string            `json:"passwordEnv" optional:"true"`
+	Cookies     map[string]string `json:"cookies" optional:"true"`
+	// AllowHTTP also sends the credentials over plain HTTP.
+	AllowHTTP bool `json:"allowHTTP" optional:"true"`
+}
+
+func compileAuth(auth []AuthConfig) ([]AuthConfig, error) {
+	out := make([]AuthConfig, 0, len(auth))
+	for i, a := range auth {
+		a.Match = strings.ToLower(strings.TrimSpace(a.Match))
+		if a.Match == "" {
+			return nil, fmt.Errorf("auth[%d]: match is required", i)
+		}
+		if _, err := path.Match(a.Match, ""); err != nil {
+			return nil, fmt.Errorf("auth[%d]: %w", i, err)
+		}
+		for name := range a.Headers {
+			if http.CanonicalHeaderKey(name) == "" || strings.ContainsAny(name, " :\r\n") {
+				return nil, fmt.Errorf("auth[%d]: invalid header name %q", i, name)
+			}
+		}
+		out = append(out, a)
+	}
+	return out, nil
+}
+
+// resolve reads the credentials from the environment. Unset variables are
+// left out, so links fail with the server's 401/403 rather than the run.
+func (a AuthConfig) resolve() web.HostAuth {
+	out := web.HostAuth{
+		Match:     a.Match,
+		Bearer:    envValue(a.BearerEnv),
+		Username:  envValue(a.UsernameEnv),
+		Password:  envValue(a.PasswordEnv),
+		AllowHTTP: a.AllowHTTP,
+	}
+	for name, env := range a.Headers {
+		if v := envValue(env); v != "" {
+			if out.Headers == nil {
+				out.Headers = make(map[string]string)
+			}
+			out.Headers[name] = v
+		}
+	}
+	for name, env := range a.Cookies {
+		if v := envValue(env); v != "" {
+			if out.Cookies == nil {
+				out.Cookies = make(map[string]string)
+			}
+			out.Cookies[name] = v
+		}
+	}
+	return out
+}
+
+func envValue(name string) string {
+	if name == "" {
+		return ""
+	}
+	return os.Getenv(name)
+}
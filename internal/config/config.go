@@ -0,0 +1,275 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"slinky/internal/fsurls"
+	"slinky/internal/web"
+)
+
+// Config holds the checker settings that live in .slinkignore next to the
+// ignorePaths/ignoreURLs rules handled by fsurls.
+type Config struct {
+	Rewrites []web.RewriteRule `json:"rewrites" optional:"true"`
+	// Migrations are rewrites that `slinky fix` applies to the source files,
+	// e.g. for a domain that moved.
+	Migrations []web.RewriteRule `json:"migrations" optional:"true"`
+	Mailto     MailtoConfig      `json:"mailto" optional:"true"`
+	Hosts      []web.HostConfig  `json:"hosts" optional:"true"`
+	Retries    RetryConfig       `json:"retries" optional:"true"`
+	Status     StatusConfig      `json:"status" optional:"true"`
+	Soft404    web.Soft404Config `json:"soft404" optional:"true"`
+	GitHub     GitHubConfig      `json:"github" optional:"true"`
+	SelfRepo   SelfRepoConfig    `json:"selfRepo" optional:"true"`
+	Cache      CacheConfig       `json:"cache" optional:"true"`
+	Auth       []AuthConfig      `json:"auth" optional:"true"`
+	TLS        TLSConfig         `json:"tls" optional:"true"`
+	// MaxRedirects bounds redirect chains (default 10).
+	MaxRedirects int `json:"maxRedirects" optional:"true"`
+	// GlobalRPS caps requests per second across all hosts.
+	GlobalRPS float64 `json:"globalRPS" optional:"true"`
+	// HTTPSUpgrade suggests https:// for working http:// links when the
+	// HTTPS form serves the same resource.
+	HTTPSUpgrade bool `json:"httpsUpgrade" optional:"true"`
+	// Timeouts bound the phases of each request.
+	Timeouts TimeoutConfig `json:"timeouts" optional:"true"`
+	// SlowThreshold warns about links slower than this duration, e.g. "3s".
+	SlowThreshold string `json:"slowThreshold" optional:"true"`
+	// Schemes declares custom URL schemes (e.g. "vscode") that are accepted
+	// when they match a pattern instead of being skipped.
+	Schemes map[string]SchemeConfig `json:"schemes" optional:"true"`
+
+	schemes map[string]web.SchemeChecker
+	root    string
+
+	slowThreshold time.Duration
+}
+
+// SchemeConfig describes a custom scheme. An empty Pattern accepts any URL of
+// the scheme.
+type SchemeConfig struct {
+	Pattern string `json:"pattern" optional:"true"`
+}
+
+// MailtoConfig controls DNS verification of mailto: recipient domains.
+type MailtoConfig struct {
+	CheckMX   bool   `json:"checkMX" optional:"true"`
+	DNSServer string `json:"dnsServer" optional:"true"`
+}
+
+// StatusConfig lists the accepted and rejected HTTP statuses for all links,
+// with overrides scoped to a host glob or URL regular expression. Overrides
+// are consulted first, in order.
+type StatusConfig struct {
+	Accept    []string           `json:"accept" optional:"true"`
+	Reject    []string           `json:"reject" optional:"true"`
+	Overrides []web.StatusPolicy `json:"overrides" optional:"true"`
+
+	policies []web.StatusPolicy
+}
+
+// GitHubConfig controls API-based checking of GitHub links. It is on by
+// default when GITHUB_TOKEN is set. APIBase defaults to GITHUB_API_URL and
+// then https://api.github.com; Hosts default to github.com, or to the host of
+// GITHUB_SERVER_URL on GitHub Enterprise Server.
+type GitHubConfig struct {
+	Enabled *bool    `json:"enabled" optional:"true"`
+	APIBase string   `json:"apiBase" optional:"true"`
+	Hosts   []string `json:"hosts" optional:"true"`
+}
+
+// CacheConfig controls the persistent result cache. Path defaults to
+// .slinky/cache/results.json; TTL and FailureTTL are Go duration strings
+// (default "24h" and "1h").
+type CacheConfig struct {
+	Enabled    bool   `json:"enabled" optional:"true"`
+	Path       string `json:"path" optional:"true"`
+	TTL        string `json:"ttl" optional:"true"`
+	FailureTTL string `json:"failureTTL" optional:"true"`
+
+	ttl, failureTTL time.Duration
+}
+
+// TimeoutConfig bounds connecting, the TLS handshake, waiting for response
+// headers and the whole request. Values are Go duration strings; unset ones
+// keep the defaults (2s, 5s, and the --timeout value for the last two).
+type TimeoutConfig struct {
+	Connect string `json:"connect" optional:"true"`
+	TLS     string `json:"tls" optional:"true"`
+	Header  string `json:"header" optional:"true"`
+	Total   string `json:"total" optional:"true"`
+
+	connect, tls, header, total time.Duration
+}
+
+// RetryConfig controls retries of transient failures. Delays are Go duration
+// strings such as "500ms" or "1m".
+type RetryConfig struct {
+	Max       *int   `json:"max" optional:"true"`
+	BaseDelay string `json:"baseDelay" optional:"true"`
+	MaxDelay  string `json:"maxDelay" optional:"true"`
+
+	baseDelay, maxDelay time.Duration
+}
+
+// Load finds the nearest .slinkignore at or above root and parses its checker
+// settings. A missing file yields an empty Config.
+func Load(root string) (Config, error) {
+	cfg := Config{root: root}
+	cfgPath, err := fsurls.ReadSlinkyConfig(root, &cfg)
+	if cfgPath == "" {
+		return cfg, nil
+	}
+	if err != nil {
+		return Config{}, fmt.Errorf("parse %s: %w", cfgPath, err)
+	}
+	rewrites, err := web.CompileRewrites(cfg.Rewrites)
+	if err != nil {
+		return Config{}, fmt.Errorf("%s: %w", cfgPath, err)
+	}
+	cfg.Rewrites = rewrites
+	if cfg.Migrations, err = web.CompileRewrites(cfg.Migrations); err != nil {
+		return Config{}, fmt.Errorf("%s: migrations: %w", cfgPath, err)
+	}
+	hosts, err := web.CompileHosts(cfg.Hosts)
+	if err != nil {
+		return Config{}, fmt.Errorf("%s: %w", cfgPath, err)
+	}
+	cfg.Hosts = hosts
+	if cfg.Auth, err = compileAuth(cfg.Auth); err != nil {
+		return Config{}, fmt.Errorf("%s: %w", cfgPath, err)
+	}
+	if err := cfg.TLS.compile(filepath.Dir(cfgPath)); err != nil {
+		return Config{}, fmt.Errorf("%s: %w", cfgPath, err)
+	}
+	policies := cfg.Status.Overrides
+	if len(cfg.Status.Accept) > 0 || len(cfg.Status.Reject) > 0 {
+		policies = append(policies, web.StatusPolicy{Accept: cfg.Status.Accept, Reject: cfg.Status.Reject})
+	}
+	if cfg.Status.policies, err = web.CompileStatusPolicies(policies); err != nil {
+		return Config{}, fmt.Errorf("%s: %w", cfgPath, err)
+	}
+	if cfg.Retries.BaseDelay != "" {
+		if cfg.Retries.baseDelay, err = time.ParseDuration(cfg.Retries.BaseDelay); err != nil {
+			return Config{}, fmt.Errorf("%s: retries.baseDelay: %w", cfgPath, err)
+		}
+	}
+	if cfg.Retries.MaxDelay != "" {
+		if cfg.Retries.maxDelay, err = time.ParseDuration(cfg.Retries.MaxDelay); err != nil {
+			return Config{}, fmt.Errorf("%s: retries.maxDelay: %w", cfgPath, err)
+		}
+	}
+	for _, d := range []struct {
+		name string
+		in   string
+		out  *time.Duration
+	}{
+		{"timeouts.connect", cfg.Timeouts.Connect, &cfg.Timeouts.connect},
+		{"timeouts.tls", cfg.Timeouts.TLS, &cfg.Timeouts.tls},
+		{"timeouts.header", cfg.Timeouts.Header, &cfg.Timeouts.header},
+		{"timeouts.total", cfg.Timeouts.Total, &cfg.Timeouts.total},
+		{"slowThreshold", cfg.SlowThreshold, &cfg.slowThreshold},
+	} {
+		if d.in == "" {
+			continue
+		}
+		if *d.out, err = time.ParseDuration(d.in); err != nil {
+			return Config{}, fmt.Errorf("%s: %s: %w", cfgPath, d.name, err)
+		}
+	}
+	if cfg.Cache.TTL != "" {
+		if cfg.Cache.ttl, err = time.ParseDuration(cfg.Cache.TTL); err != nil {
+			return Config{}, fmt.Errorf("%s: cache.ttl: %w", cfgPath, err)
+		}
+	}
+	if cfg.Cache.FailureTTL != "" {
+		if cfg.Cache.failureTTL, err = time.ParseDuration(cfg.Cache.FailureTTL); err != nil {
+			return Config{}, fmt.Errorf("%s: cache.failureTTL: %w", cfgPath, err)
+		}
+	}
+	if len(cfg.Schemes) > 0 {
+		cfg.schemes = make(map[string]web.SchemeChecker, len(cfg.Schemes))
+		for name, sc := range cfg.Schemes {
+			var re *regexp.Regexp
+			if sc.Pattern != "" {
+				if re, err = regexp.Compile(sc.Pattern); err != nil {
+					return Config{}, fmt.Errorf("%s: scheme %q: %w", cfgPath, name, err)
+				}
+			}
+			cfg.schemes[name] = web.PatternSchemeChecker{Pattern: re}
+		}
+	}
+	return cfg, nil
+}
+
+// Apply copies the loaded settings onto a checker configuration.
+func (c Config) Apply(wc *web.Config) {
+	wc.Rewrites = c.Rewrites
+	wc.CheckMX = c.Mailto.CheckMX
+	wc.DNSServer = c.Mailto.DNSServer
+	wc.Hosts = c.Hosts
+	wc.GlobalRPS = c.GlobalRPS
+	wc.MaxRedirects = c.MaxRedirects
+	wc.HTTPSUpgrade = c.HTTPSUpgrade
+	wc.Soft404 = c.Soft404
+	wc.GitHub = c.GitHub.resolve()
+	wc.SelfRepo = c.SelfRepo.resolve(c.root, wc.GitHub.Hosts)
+	wc.StatusPolicies = c.Status.policies
+	if c.Retries.Max != nil {
+		wc.MaxRetries = *c.Retries.Max
+	}
+	wc.RetryBaseDelay = c.Retries.baseDelay
+	wc.RetryMaxDelay = c.Retries.maxDelay
+	wc.Schemes = c.schemes
+	wc.Auth = nil
+	for _, a := range c.Auth {
+		wc.Auth = append(wc.Auth, a.resolve())
+	}
+	wc.TLS = c.TLS.compiled
+	if c.Timeouts.total > 0 {
+		wc.RequestTimeout = c.Timeouts.total
+	}
+	wc.ConnectTimeout = c.Timeouts.connect
+	wc.TLSTimeout = c.Timeouts.tls
+	wc.HeaderTimeout = c.Timeouts.header
+	wc.SlowThreshold = c.slowThreshold
+	wc.CacheTTL = c.Cache.ttl
+	wc.CacheFailTTL = c.Cache.failureTTL
+}
+
+// CachePath returns the configured cache file, or "" when caching is off.
+func (c Config) CachePath() string {
+	if !c.Cache.Enabled && c.Cache.Path == "" {
+		return ""
+	}
+	if c.Cache.Path != "" {
+		return c.Cache.Path
+	}
+	return web.DefaultCachePath
+}
+
+func (g GitHubConfig) resolve() web.GitHubConfig {
+	out := web.GitHubConfig{
+		Token:   os.Getenv("GITHUB_TOKEN"),
+		APIBase: g.APIBase,
+		Hosts:   g.Hosts,
+	}
+	out.Enabled = out.Token != ""
+	if g.Enabled != nil {
+		out.Enabled = *g.Enabled
+	}
+	if out.APIBase == "" {
+		out.APIBase = os.Getenv("GITHUB_API_URL")
+	}
+	if len(out.Hosts) == 0 {
+		if u, err := url.Parse(os.Getenv("GITHUB_SERVER_URL")); err == nil && u.Hostname() != "" && u.Hostname() != "github.com" {
+			out.Hosts = []string{u.Hostname()}
+		}
+	}
+	return out
+}
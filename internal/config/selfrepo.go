@@ -0,0 +1,217 @@
+package config
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"slinky/internal/web"
+)
+
+// SelfRepoConfig controls checking links to the scanned repository against
+// the working tree. It is on by default whenever the repository can be
+// identified.
+type SelfRepoConfig struct {
+	Enabled *bool `json:"enabled" optional:"true"`
+	// Repository is "owner/repo"; it defaults to GITHUB_REPOSITORY, then the
+	// origin remote.
+	Repository string `json:"repository" optional:"true"`
+	// Refs the working tree stands in for, taken as given; defaults to the
+	// current branch and the default branch that origin/HEAD points at.
+	Refs []string `json:"refs" optional:"true"`
+}
+
+// remoteRegex extracts host, owner and repository from https, ssh and scp-like
+// remote URLs.
+var remoteRegex = regexp.MustCompile(`^(?:[a-z+]+://)?(?:[^@/]+@)?([^/:]+)(?::\d+)?[:/]([^/]+)/([^/]+?)(?:\.git)?/?$`)
+
+func (s SelfRepoConfig) resolve(root string, hosts []string) web.SelfRepoConfig {
+	if s.Enabled != nil && !*s.Enabled {
+		return web.SelfRepoConfig{}
+	}
+	if len(hosts) == 0 {
+		hosts = []string{"github.com"}
+	}
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return web.SelfRepoConfig{}
+	}
+	top, gitDir := findGitDir(abs)
+	out := web.SelfRepoConfig{Root: top, Hosts: hosts}
+	if top == "" {
+		out.Root = abs
+		if ws := os.Getenv("GITHUB_WORKSPACE"); ws != "" {
+			out.Root = ws
+		}
+	}
+
+	repo := s.Repository
+	if repo == "" {
+		repo = os.Getenv("GITHUB_REPOSITORY")
+	}
+	if repo != "" {
+		out.Owner, out.Repo, _ = strings.Cut(repo, "/")
+	} else if gitDir != "" {
+		out.Owner, out.Repo = originRepo(gitDir, hosts)
+	}
+	if out.Owner == "" || out.Repo == "" {
+		return web.SelfRepoConfig{}
+	}
+
+	out.Refs = s.Refs
+	if len(out.Refs) == 0 {
+		out.Refs = defaultRefs(top, gitDir)
+	}
+	return out
+}
+
+// defaultRefs returns the checked-out branch and the default branch. Branch
+// names from the CI environment are only added once git confirms they exist,
+// so a stale or mistyped name never hides a broken link.
+func defaultRefs(top, gitDir string) []string {
+	var refs []string
+	add := func(ref string) {
+		for _, r := range refs {
+			if r == ref {
+				return
+			}
+		}
+		refs = append(refs, ref)
+	}
+	if b := currentBranch(gitDir); b != "" {
+		add(b)
+	}
+	if b := defaultBranch(gitDir); b != "" {
+		add(b)
+	}
+	for _, b := range []string{os.Getenv("GITHUB_HEAD_REF"), os.Getenv("GITHUB_REF_NAME")} {
+		if b != "" && refExists(top, b) {
+			add(b)
+		}
+	}
+	return refs
+}
+
+// defaultBranch reads the branch origin/HEAD points at, as set by git clone or
+// git remote set-head.
+func defaultBranch(gitDir string) string {
+	if gitDir == "" {
+		return ""
+	}
+	b, err := os.ReadFile(filepath.Join(commonDir(gitDir), "refs", "remotes", "origin", "HEAD"))
+	if err != nil {
+		return ""
+	}
+	branch, ok := strings.CutPrefix(strings.TrimSpace(string(b)), "ref: refs/remotes/origin/")
+	if !ok {
+		return ""
+	}
+	return branch
+}
+
+// refExists asks git whether ref names a commit, locally or on origin.
+func refExists(top, ref string) bool {
+	if top == "" || strings.HasPrefix(ref, "-") {
+		return false
+	}
+	for _, r := range []string{ref, "refs/remotes/origin/" + ref} {
+		if exec.Command("git", "-C", top, "rev-parse", "--verify", "--quiet", r+"^{commit}").Run() == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// commonDir returns the directory holding the refs and config shared by all
+// worktrees of a repository.
+func commonDir(gitDir string) string {
+	b, err := os.ReadFile(filepath.Join(gitDir, "commondir"))
+	if err != nil {
+		return gitDir
+	}
+	common := strings.TrimSpace(string(b))
+	if !filepath.IsAbs(common) {
+		common = filepath.Join(gitDir, common)
+	}
+	return common
+}
+
+// findGitDir walks up from dir to the checkout root. It returns the root and
+// the git directory, following the "gitdir:" file used by worktrees.
+func findGitDir(dir string) (top, gitDir string) {
+	for {
+		p := filepath.Join(dir, ".git")
+		if st, err := os.Stat(p); err == nil {
+			if st.IsDir() {
+				return dir, p
+			}
+			if b, err := os.ReadFile(p); err == nil {
+				if target, ok := strings.CutPrefix(strings.TrimSpace(string(b)), "gitdir:"); ok {
+					target = strings.TrimSpace(target)
+					if !filepath.IsAbs(target) {
+						target = filepath.Join(dir, target)
+					}
+					return dir, target
+				}
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", ""
+		}
+		dir = parent
+	}
+}
+
+// originRepo reads the origin remote from the git config.
+func originRepo(gitDir string, hosts []string) (owner, repo string) {
+	// Worktrees keep the shared config in the common directory
+	f, err := os.Open(filepath.Join(commonDir(gitDir), "config"))
+	if err != nil {
+		return "", ""
+	}
+	defer f.Close()
+	inOrigin := false
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if strings.HasPrefix(line, "[") {
+			inOrigin = line == `[remote "origin"]`
+			continue
+		}
+		key, val, ok := strings.Cut(line, "=")
+		if !inOrigin || !ok || strings.TrimSpace(key) != "url" {
+			continue
+		}
+		m := remoteRegex.FindStringSubmatch(strings.TrimSpace(val))
+		if m == nil {
+			return "", ""
+		}
+		for _, h := range hosts {
+			if strings.EqualFold(h, m[1]) {
+				return m[2], m[3]
+			}
+		}
+		return "", ""
+	}
+	return "", ""
+}
+
+func currentBranch(gitDir string) string {
+	if gitDir == "" {
+		return ""
+	}
+	b, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return ""
+	}
+	branch, _ := strings.CutPrefix(strings.TrimSpace(string(b)), "ref: refs/heads/")
+	if branch == strings.TrimSpace(string(b)) {
+		// Detached HEAD
+		return ""
+	}
+	return branch
+}
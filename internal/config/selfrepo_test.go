@@ -0,0 +1,86 @@
+package config
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSelfRepoResolve_FromGitRemote(t *testing.T) {
+	t.Setenv("GITHUB_REPOSITORY", "")
+	t.Setenv("GITHUB_HEAD_REF", "")
+	t.Setenv("GITHUB_REF_NAME", "")
+	remotes := map[string]string{
+		"https://github.com/ourorg/ourrepo.git": "ourorg/ourrepo",
+		"git@github.com:ourorg/ourrepo.git":     "ourorg/ourrepo",
+		"ssh://git@github.com/ourorg/ourrepo":   "ourorg/ourrepo",
+		"https://gitlab.com/ourorg/ourrepo.git": "",
+	}
+	for remote, want := range remotes {
+		root := t.TempDir()
+		gitDir := filepath.Join(root, ".git")
+		if err := os.MkdirAll(gitDir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		cfg := "[core]\n\tbare = false\n[remote \"origin\"]\n\turl = " + remote + "\n\tfetch = +refs/heads/*:refs/remotes/origin/*\n"
+		if err := os.WriteFile(filepath.Join(gitDir, "config"), []byte(cfg), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(gitDir, "HEAD"), []byte("ref: refs/heads/fix/links\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(filepath.Join(gitDir, "refs", "remotes", "origin"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(gitDir, "refs", "remotes", "origin", "HEAD"), []byte("ref: refs/remotes/origin/trunk\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		sub := filepath.Join(root, "docs")
+		if err := os.MkdirAll(sub, 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		got := SelfRepoConfig{}.resolve(sub, nil)
+		if want == "" {
+			if got.Owner != "" {
+				t.Fatalf("%s: expected no self repo, got %+v", remote, got)
+			}
+			continue
+		}
+		if got.Owner+"/"+got.Repo != want || got.Root != root {
+			t.Fatalf("%s: got %+v", remote, got)
+		}
+		if refs := strings.Join(got.Refs, " "); refs != "fix/links trunk" {
+			t.Fatalf("%s: refs %v, want the current and default branch", remote, refs)
+		}
+	}
+}
+
+func TestSelfRepoResolve_VerifiesCIRefs(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	root := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", root, "-c", "user.name=t", "-c", "user.email=t@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	git("init", "-q", "-b", "work")
+	git("commit", "-q", "--allow-empty", "-m", "init")
+	git("branch", "feature")
+	git("checkout", "-q", "--detach")
+	git("remote", "add", "origin", "https://github.com/ourorg/ourrepo.git")
+
+	t.Setenv("GITHUB_REPOSITORY", "")
+	t.Setenv("GITHUB_HEAD_REF", "feature")
+	t.Setenv("GITHUB_REF_NAME", "42/merge")
+	got := SelfRepoConfig{}.resolve(root, nil)
+	if refs := strings.Join(got.Refs, " "); refs != "feature" {
+		t.Fatalf("refs %q, want only the CI branch that exists", refs)
+	}
+}
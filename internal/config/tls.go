@@ -0,0 +1,78 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"slinky/internal/web"
+)
+
+// TLSConfig adds CA roots and per-host client certificates or verification
+// settings. File paths are relative to the directory holding .slinkignore.
+type TLSConfig struct {
+	CAFiles []string        `json:"caFiles" optional:"true"`
+	Hosts   []HostTLSConfig `json:"hosts" optional:"true"`
+
+	compiled web.TLSConfig
+}
+
+// HostTLSConfig applies to hostnames matching the Match glob.
+type HostTLSConfig struct {
+	Match              string `json:"match"`
+	CertFile           string `json:"certFile" optional:"true"`
+	KeyFile            string `json:"keyFile" optional:"true"`
+	InsecureSkipVerify bool   `json:"insecureSkipVerify" optional:"true"`
+}
+
+// compile loads the CA bundles and key pairs named in t.
+func (t *TLSConfig) compile(dir string) error {
+	resolve := func(p string) string {
+		if filepath.IsAbs(p) {
+			return p
+		}
+		return filepath.Join(dir, p)
+	}
+	if len(t.CAFiles) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		for _, f := range t.CAFiles {
+			pem, err := os.ReadFile(resolve(f))
+			if err != nil {
+				return fmt.Errorf("tls.caFiles: %w", err)
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return fmt.Errorf("tls.caFiles: %s: no PEM certificates found", f)
+			}
+		}
+		t.compiled.RootCAs = pool
+	}
+	for i, h := range t.Hosts {
+		match := strings.ToLower(strings.TrimSpace(h.Match))
+		if match == "" {
+			return fmt.Errorf("tls.hosts[%d]: match is required", i)
+		}
+		if _, err := path.Match(match, ""); err != nil {
+			return fmt.Errorf("tls.hosts[%d]: %w", i, err)
+		}
+		if (h.CertFile == "") != (h.KeyFile == "") {
+			return fmt.Errorf("tls.hosts[%d]: certFile and keyFile must be set together", i)
+		}
+		ht := web.HostTLS{Match: match, InsecureSkipVerify: h.InsecureSkipVerify}
+		if h.CertFile != "" {
+			cert, err := tls.LoadX509KeyPair(resolve(h.CertFile), resolve(h.KeyFile))
+			if err != nil {
+				return fmt.Errorf("tls.hosts[%d]: %w", i, err)
+			}
+			ht.Certificate = &cert
+		}
+		t.compiled.Hosts = append(t.compiled.Hosts, ht)
+	}
+	return nil
+}
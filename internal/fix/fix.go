@@ -0,0 +1,93 @@
+// Package fix rewrites URLs inside source files at recorded byte spans.
+package fix
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Edit replaces Old with New at content[Start:End].
+type Edit struct {
+	Start, End int
+	Old, New   string
+}
+
+// Apply returns content with edits applied. Edits whose span no longer holds
+// Old, or that overlap an earlier edit, are returned as skipped and leave the
+// content untouched.
+func Apply(content []byte, edits []Edit) ([]byte, []Edit) {
+	sorted := append([]Edit(nil), edits...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Start < sorted[j].Start })
+	var (
+		out     bytes.Buffer
+		skipped []Edit
+		pos     int
+	)
+	for _, e := range sorted {
+		if e.Start < pos || e.End > len(content) || e.Start > e.End || string(content[e.Start:e.End]) != e.Old {
+			skipped = append(skipped, e)
+			continue
+		}
+		out.Write(content[pos:e.Start])
+		out.WriteString(e.New)
+		pos = e.End
+	}
+	out.Write(content[pos:])
+	return out.Bytes(), skipped
+}
+
+// UnifiedDiff renders the line changes between before and after as a unified
+// diff with three lines of context. Edits never add or remove newlines, so
+// lines correspond one to one. It returns "" when nothing changed.
+func UnifiedDiff(path string, before, after []byte) string {
+	a := strings.SplitAfter(string(before), "\n")
+	b := strings.SplitAfter(string(after), "\n")
+	if len(a) != len(b) {
+		return fmt.Sprintf("--- a/%s\n+++ b/%s\n(line count changed; diff unavailable)\n", path, path)
+	}
+	var changed []int
+	for i := range a {
+		if a[i] != b[i] {
+			changed = append(changed, i)
+		}
+	}
+	if len(changed) == 0 {
+		return ""
+	}
+	const context = 3
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- a/%s\n+++ b/%s\n", path, path)
+	for i := 0; i < len(changed); {
+		// Merge changes whose context windows touch into one hunk
+		j := i
+		for j+1 < len(changed) && changed[j+1]-changed[j] <= 2*context {
+			j++
+		}
+		start := max(changed[i]-context, 0)
+		end := min(changed[j]+context+1, len(a))
+		if end == len(a) && a[end-1] == "" {
+			end--
+		}
+		fmt.Fprintf(&sb, "@@ -%d,%d +%d,%d @@\n", start+1, end-start, start+1, end-start)
+		for k := start; k < end; k++ {
+			if a[k] == b[k] {
+				writeDiffLine(&sb, " ", a[k])
+				continue
+			}
+			writeDiffLine(&sb, "-", a[k])
+			writeDiffLine(&sb, "+", b[k])
+		}
+		i = j + 1
+	}
+	return sb.String()
+}
+
+func writeDiffLine(sb *strings.Builder, prefix, line string) {
+	sb.WriteString(prefix)
+	sb.WriteString(line)
+	if !strings.HasSuffix(line, "\n") {
+		sb.WriteString("\n\\ No newline at end of file\n")
+	}
+}
@@ -0,0 +1,34 @@
+package fix
+
+import "testing"
+
+func TestApply(t *testing.T) {
+	content := []byte("see [a](http://old.example/a) and http://old.example/a.\n")
+	edits := []Edit{
+		{Start: 8, End: 28, Old: "http://old.example/a", New: "https://new.example/a"},
+		{Start: 34, End: 54, Old: "http://old.example/a", New: "https://new.example/a"},
+		{Start: 0, End: 3, Old: "xyz", New: "abc"},
+	}
+	got, skipped := Apply(content, edits)
+	want := "see [a](https://new.example/a) and https://new.example/a.\n"
+	if string(got) != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	if len(skipped) != 1 || skipped[0].Old != "xyz" {
+		t.Fatalf("expected the stale edit to be skipped, got %+v", skipped)
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	before := "1\n2\n3\n4\nold\n6\n7\n8\n9\n10\n11\n12\nold\n"
+	after := "1\n2\n3\n4\nnew\n6\n7\n8\n9\n10\n11\n12\nnew\n"
+	want := "--- a/doc.md\n+++ b/doc.md\n" +
+		"@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-old\n+new\n 6\n 7\n 8\n" +
+		"@@ -10,4 +10,4 @@\n 10\n 11\n 12\n-old\n+new\n"
+	if got := UnifiedDiff("doc.md", []byte(before), []byte(after)); got != want {
+		t.Fatalf("diff:\n%s\nwant:\n%s", got, want)
+	}
+	if got := UnifiedDiff("doc.md", []byte(before), []byte(before)); got != "" {
+		t.Fatalf("expected empty diff, got %q", got)
+	}
+}
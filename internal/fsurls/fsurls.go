@@ -9,7 +9,6 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
-	"sort"
 	"strings"
 
 	"github.com/bmatcuk/doublestar/v4"
@@ -17,13 +16,18 @@ import (
 )
 
 // URL patterns from various contexts
-var bareURLRegex = regexp.MustCompile(`(?i)\bhttps?://[^\s<>\[\]{}"']+`)
+var bareURLRegex = regexp.MustCompile(`(?i)\b(?:https?|ftp)://[^\s<>\[\]{}"']+`)
 var mdLinkRegex = regexp.MustCompile(`(?is)!?\[[^\]]*\]\((.*?)\)`) // captures (url)
-var angleURLRegex = regexp.MustCompile(`(?i)<(https?://[^>\s]+)>`)
-var quotedURLRegex = regexp.MustCompile(`(?i)"(https?://[^"\s]+)"|'(https?://[^'\s]+)'`)
+var angleURLRegex = regexp.MustCompile(`(?i)<((?:https?|ftp)://[^>\s]+)>`)
+var quotedURLRegex = regexp.MustCompile(`(?i)"((?:https?|ftp)://[^"\s]+)"|'((?:https?|ftp)://[^'\s]+)'`)
 var htmlHrefRegex = regexp.MustCompile(`(?i)href\s*=\s*"([^"]+)"|href\s*=\s*'([^']+)'`)
 var htmlSrcRegex = regexp.MustCompile(`(?i)src\s*=\s*"([^"]+)"|src\s*=\s*'([^']+)'`)
 
+// customSchemeRegex matches hierarchical URLs of any scheme; these are only
+// taken from explicit link syntax (href, src, markdown links).
+var customSchemeRegex = regexp.MustCompile(`^(?i)[a-z][a-z0-9+.-]*://[^\s]+$`)
+var mailtoRegex = regexp.MustCompile(`(?i)\bmailto:[^\s<>\[\]{}"'()]+`)
+
 // Strict hostname validation: labels 1-63 chars, alnum & hyphen, not start/end hyphen, at least one dot, simple TLD
 var hostnameRegex = regexp.MustCompile(`^(?i)([a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?)(?:\.[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?)+$`)
 
@@ -43,14 +47,14 @@ func isDebugEnv() bool {
 // CollectURLs walks the directory tree rooted at rootPath and collects URLs found in
 // text-based files matching any of the provided glob patterns (doublestar ** supported).
 // If globs is empty, all files are considered. Respects .gitignore if present and respectGitignore=true.
-// Returns a map from URL -> sorted unique list of file paths that contained it.
-func CollectURLs(rootPath string, globs []string, respectGitignore bool) (map[string][]string, error) {
+// Returns a map from URL -> every occurrence of it, sorted by file and position.
+func CollectURLs(rootPath string, globs []string, respectGitignore bool) (map[string][]Source, error) {
 	return CollectURLsWithIgnore(rootPath, globs, respectGitignore, nil, nil)
 }
 
 // CollectURLsWithIgnore is like CollectURLs but accepts pre-loaded ignore configuration
 // to avoid reloading .slinkignore and .gitignore multiple times.
-func CollectURLsWithIgnore(rootPath string, globs []string, respectGitignore bool, slPathIgnore *ignore.GitIgnore, slURLPatterns []string) (map[string][]string, error) {
+func CollectURLsWithIgnore(rootPath string, globs []string, respectGitignore bool, slPathIgnore *ignore.GitIgnore, slURLPatterns []string) (map[string][]Source, error) {
 	return CollectURLsWithIgnoreConfig(rootPath, globs, respectGitignore, nil, slPathIgnore, slURLPatterns)
 }
 
@@ -145,7 +149,7 @@ func shouldSkipDirectory(rel string, ignorePatterns []string) bool {
 
 // CollectURLsWithIgnoreConfig accepts all pre-loaded ignore configuration
 // to avoid reloading .gitignore and .slinkignore multiple times.
-func CollectURLsWithIgnoreConfig(rootPath string, globs []string, respectGitignore bool, gitIgnore *ignore.GitIgnore, slPathIgnore *ignore.GitIgnore, slURLPatterns []string) (map[string][]string, error) {
+func CollectURLsWithIgnoreConfig(rootPath string, globs []string, respectGitignore bool, gitIgnore *ignore.GitIgnore, slPathIgnore *ignore.GitIgnore, slURLPatterns []string) (map[string][]Source, error) {
 	if strings.TrimSpace(rootPath) == "" {
 		rootPath = "."
 	}
@@ -166,6 +170,7 @@ func CollectURLsWithIgnoreConfig(rootPath string, globs []string, respectGitigno
 	if slPathIgnore == nil {
 		slPathIgnore, slURLPatterns = LoadSlinkyIgnore(cleanRoot)
 	}
+	opts := loadExtractOptions(cleanRoot)
 
 	var patterns []string
 	for _, g := range globs {
@@ -189,7 +194,7 @@ func CollectURLsWithIgnoreConfig(rootPath string, globs []string, respectGitigno
 		return false
 	}
 
-	urlToFiles := make(map[string]map[string]struct{})
+	sources := make(sourceSet)
 
 	// 2 MiB max file size to avoid huge/binary files
 	const maxSize = 2 * 1024 * 1024
@@ -268,59 +273,29 @@ func CollectURLsWithIgnoreConfig(rootPath string, globs []string, respectGitigno
 			return nil
 		}
 
-		matches := extractCandidateMatches(content)
-		if len(matches) == 0 {
-			return nil
-		}
-		for _, m := range matches {
-			u := sanitizeURLToken(m.URL)
-			if u == "" {
-				continue
-			}
-			if isURLIgnored(u, slURLPatterns) {
-				continue
-			}
-			line, col := computeLineCol(content, m.Offset)
-			source := fmt.Sprintf("%s|%d|%d", rel, line, col)
-			fileSet, ok := urlToFiles[u]
-			if !ok {
-				fileSet = make(map[string]struct{})
-				urlToFiles[u] = fileSet
-			}
-			fileSet[source] = struct{}{}
-		}
+		collectFileSources(sources, path, rel, content, opts, slURLPatterns)
 		return nil
 	}
 
 	_ = filepath.WalkDir(cleanRoot, walkFn)
 
-	// Convert to sorted slices
-	result := make(map[string][]string, len(urlToFiles))
-	for u, files := range urlToFiles {
-		var list []string
-		for fp := range files {
-			list = append(list, fp)
-		}
-		sort.Strings(list)
-		result[u] = list
-	}
-	return result, nil
+	return sources.sorted(), nil
 }
 
 // CollectURLsProgress is like CollectURLs but invokes onFile(relPath) for each included file.
-func CollectURLsProgress(rootPath string, globs []string, respectGitignore bool, onFile func(string)) (map[string][]string, error) {
+func CollectURLsProgress(rootPath string, globs []string, respectGitignore bool, onFile func(string)) (map[string][]Source, error) {
 	return CollectURLsProgressWithIgnore(rootPath, globs, respectGitignore, onFile, nil, nil)
 }
 
 // CollectURLsProgressWithIgnore is like CollectURLsProgress but accepts pre-loaded ignore configuration
 // to avoid reloading .slinkignore and .gitignore multiple times.
-func CollectURLsProgressWithIgnore(rootPath string, globs []string, respectGitignore bool, onFile func(string), slPathIgnore *ignore.GitIgnore, slURLPatterns []string) (map[string][]string, error) {
+func CollectURLsProgressWithIgnore(rootPath string, globs []string, respectGitignore bool, onFile func(string), slPathIgnore *ignore.GitIgnore, slURLPatterns []string) (map[string][]Source, error) {
 	return CollectURLsProgressWithIgnoreConfig(rootPath, globs, respectGitignore, onFile, nil, slPathIgnore, slURLPatterns)
 }
 
 // CollectURLsProgressWithIgnoreConfig accepts all pre-loaded ignore configuration
 // to avoid reloading .gitignore and .slinkignore multiple times.
-func CollectURLsProgressWithIgnoreConfig(rootPath string, globs []string, respectGitignore bool, onFile func(string), gitIgnore *ignore.GitIgnore, slPathIgnore *ignore.GitIgnore, slURLPatterns []string) (map[string][]string, error) {
+func CollectURLsProgressWithIgnoreConfig(rootPath string, globs []string, respectGitignore bool, onFile func(string), gitIgnore *ignore.GitIgnore, slPathIgnore *ignore.GitIgnore, slURLPatterns []string) (map[string][]Source, error) {
 	if strings.TrimSpace(rootPath) == "" {
 		rootPath = "."
 	}
@@ -341,6 +316,7 @@ func CollectURLsProgressWithIgnoreConfig(rootPath string, globs []string, respec
 	if slPathIgnore == nil {
 		slPathIgnore, slURLPatterns = LoadSlinkyIgnore(cleanRoot)
 	}
+	opts := loadExtractOptions(cleanRoot)
 
 	var patterns []string
 	for _, g := range globs {
@@ -364,7 +340,7 @@ func CollectURLsProgressWithIgnoreConfig(rootPath string, globs []string, respec
 		return false
 	}
 
-	urlToFiles := make(map[string]map[string]struct{})
+	sources := make(sourceSet)
 
 	// 2 MiB max file size to avoid huge/binary files
 	const maxSize = 2 * 1024 * 1024
@@ -447,42 +423,13 @@ func CollectURLsProgressWithIgnoreConfig(rootPath string, globs []string, respec
 			return nil
 		}
 
-		matches := extractCandidateMatches(content)
-		if len(matches) == 0 {
-			return nil
-		}
-		for _, m := range matches {
-			u := sanitizeURLToken(m.URL)
-			if u == "" {
-				continue
-			}
-			if isURLIgnored(u, slURLPatterns) {
-				continue
-			}
-			line, col := computeLineCol(content, m.Offset)
-			source := fmt.Sprintf("%s|%d|%d", rel, line, col)
-			fileSet, ok := urlToFiles[u]
-			if !ok {
-				fileSet = make(map[string]struct{})
-				urlToFiles[u] = fileSet
-			}
-			fileSet[source] = struct{}{}
-		}
+		collectFileSources(sources, path, rel, content, opts, slURLPatterns)
 		return nil
 	}
 
 	_ = filepath.WalkDir(cleanRoot, walkFn)
 
-	result := make(map[string][]string, len(urlToFiles))
-	for u, files := range urlToFiles {
-		var list []string
-		for fp := range files {
-			list = append(list, fp)
-		}
-		sort.Strings(list)
-		result[u] = list
-	}
-	return result, nil
+	return sources.sorted(), nil
 }
 
 func sanitizeURLToken(s string) string {
@@ -497,7 +444,32 @@ func sanitizeURLToken(s string) string {
 	// Trim obvious invalid chars at both ends and balance brackets/parentheses
 	s = trimDelimiters(s)
 	low := strings.ToLower(s)
-	if !(strings.HasPrefix(low, "http://") || strings.HasPrefix(low, "https://")) {
+	// mailto: syntax is validated by the checker so malformed addresses get reported
+	if strings.HasPrefix(low, "mailto:") {
+		if len(s) > len("mailto:") {
+			return s
+		}
+		return ""
+	}
+	// Local targets (e.g. resolved OpenAPI $refs) only need a usable path
+	if strings.HasPrefix(low, "file:") {
+		if u, err := url.Parse(s); err == nil && (u.Path != "" || u.Opaque != "") {
+			return s
+		}
+		return ""
+	}
+	// data: payloads are validated by the checker
+	if strings.HasPrefix(low, "data:") {
+		if strings.Contains(s, ",") {
+			return s
+		}
+		return ""
+	}
+	if !(strings.HasPrefix(low, "http://") || strings.HasPrefix(low, "https://") || strings.HasPrefix(low, "ftp://")) {
+		// Other schemes are kept so the checker can dispatch or skip them
+		if customSchemeRegex.MatchString(s) {
+			return s
+		}
 		return ""
 	}
 	// Parse and validate hostname strictly
@@ -624,10 +596,24 @@ func trimDelimiters(s string) string {
 	return s
 }
 
-// matchCandidate holds a URL and its byte offset within the content
+// matchCandidate holds a URL and its byte offset within the content. Length is
+// the length of the matched text when it differs from len(URL) (e.g. resolved
+// relative targets). KeyPath is set for URLs inside values of structured files
+// (JSON/YAML/TOML).
 type matchCandidate struct {
-	URL    string
-	Offset int
+	URL       string
+	Offset    int
+	Length    int
+	Extractor string
+	Context   string
+	KeyPath   string
+}
+
+func (m matchCandidate) length() int {
+	if m.Length > 0 {
+		return m.Length
+	}
+	return len(m.URL)
 }
 
 // computeLineCol returns 1-based line and column given a byte offset
@@ -651,74 +637,116 @@ func computeLineCol(content string, offset int) (int, int) {
 	return line, col
 }
 
-// extractCandidateMatches finds URL-like tokens with their offsets for line/col mapping
+// extractFileMatches picks the extractor for a file based on its name and
+// content, falling back to the generic pattern-based extractor.
+func extractFileMatches(path string, content string, opts extractOptions) []matchCandidate {
+	matches, ok := extractOpenAPIMatches(path, content, opts)
+	if !ok {
+		matches, ok = extractMarkupMatches(path, content)
+	}
+	if !ok {
+		matches, ok = extractLaTeXMatches(path, content)
+	}
+	if !ok {
+		matches = extractCandidateMatches(content)
+	}
+	annotateKeyPaths(path, content, matches)
+	out := matches[:0]
+	for _, m := range matches {
+		if opts.keys.Skip(m.KeyPath) {
+			continue
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+// extractCandidateMatches finds URL-like tokens with their offsets for line/col mapping.
+// Explicit link syntaxes come first so they win over the bare match at the same position.
 func extractCandidateMatches(content string) []matchCandidate {
 	var out []matchCandidate
+	add := func(start, end int, context string) {
+		out = append(out, matchCandidate{URL: content[start:end], Offset: start, Extractor: "generic", Context: context})
+	}
+	// addAlt handles patterns whose groups 1 and 2 are alternatives
+	addAlt := func(idx []int, context string) {
+		if len(idx) >= 4 && idx[2] >= 0 && idx[3] >= 0 {
+			add(idx[2], idx[3], context)
+		} else if len(idx) >= 6 && idx[4] >= 0 && idx[5] >= 0 {
+			add(idx[4], idx[5], context)
+		}
+	}
 	// Markdown links: capture group 1 is the URL inside (...)
-	if subs := mdLinkRegex.FindAllStringSubmatchIndex(content, -1); len(subs) > 0 {
-		for _, idx := range subs {
-			if len(idx) >= 4 && idx[2] >= 0 && idx[3] >= 0 {
-				url := content[idx[2]:idx[3]]
-				out = append(out, matchCandidate{URL: url, Offset: idx[2]})
+	for _, idx := range mdLinkRegex.FindAllStringSubmatchIndex(content, -1) {
+		if len(idx) >= 4 && idx[2] >= 0 && idx[3] >= 0 {
+			context := ContextMarkdownLink
+			if content[idx[0]] == '!' {
+				context = ContextMarkdownImage
 			}
+			add(idx[2], idx[3], context)
 		}
 	}
-	// HTML href
-	if subs := htmlHrefRegex.FindAllStringSubmatchIndex(content, -1); len(subs) > 0 {
-		for _, idx := range subs {
-			// groups 1 and 2 are alternatives
-			if len(idx) >= 4 && idx[2] >= 0 && idx[3] >= 0 {
-				url := content[idx[2]:idx[3]]
-				out = append(out, matchCandidate{URL: url, Offset: idx[2]})
-			} else if len(idx) >= 6 && idx[4] >= 0 && idx[5] >= 0 {
-				url := content[idx[4]:idx[5]]
-				out = append(out, matchCandidate{URL: url, Offset: idx[4]})
-			}
+	// HTML href; <link rel="stylesheet"> gets its own context
+	for _, idx := range htmlHrefRegex.FindAllStringSubmatchIndex(content, -1) {
+		context := ContextHref
+		if name, tag := enclosingTag(content, idx[0]); name == "link" && stylesheetRelRegex.MatchString(tag) {
+			context = ContextStylesheet
 		}
+		addAlt(idx, context)
 	}
-	// HTML src
-	if subs := htmlSrcRegex.FindAllStringSubmatchIndex(content, -1); len(subs) > 0 {
-		for _, idx := range subs {
-			if len(idx) >= 4 && idx[2] >= 0 && idx[3] >= 0 {
-				url := content[idx[2]:idx[3]]
-				out = append(out, matchCandidate{URL: url, Offset: idx[2]})
-			} else if len(idx) >= 6 && idx[4] >= 0 && idx[5] >= 0 {
-				url := content[idx[4]:idx[5]]
-				out = append(out, matchCandidate{URL: url, Offset: idx[4]})
-			}
+	// HTML src, distinguishing images and scripts
+	for _, idx := range htmlSrcRegex.FindAllStringSubmatchIndex(content, -1) {
+		context := ContextSrc
+		switch name, _ := enclosingTag(content, idx[0]); name {
+		case "img":
+			context = ContextImgSrc
+		case "script":
+			context = ContextScriptSrc
 		}
+		addAlt(idx, context)
 	}
 	// Angle autolinks <http://...>
-	if subs := angleURLRegex.FindAllStringSubmatchIndex(content, -1); len(subs) > 0 {
-		for _, idx := range subs {
-			if len(idx) >= 4 && idx[2] >= 0 && idx[3] >= 0 {
-				url := content[idx[2]:idx[3]]
-				out = append(out, matchCandidate{URL: url, Offset: idx[2]})
-			}
+	for _, idx := range angleURLRegex.FindAllStringSubmatchIndex(content, -1) {
+		if len(idx) >= 4 && idx[2] >= 0 && idx[3] >= 0 {
+			add(idx[2], idx[3], ContextAutolink)
 		}
 	}
 	// Quoted URLs
-	if subs := quotedURLRegex.FindAllStringSubmatchIndex(content, -1); len(subs) > 0 {
-		for _, idx := range subs {
-			if len(idx) >= 4 && idx[2] >= 0 && idx[3] >= 0 {
-				url := content[idx[2]:idx[3]]
-				out = append(out, matchCandidate{URL: url, Offset: idx[2]})
-			} else if len(idx) >= 6 && idx[4] >= 0 && idx[5] >= 0 {
-				url := content[idx[4]:idx[5]]
-				out = append(out, matchCandidate{URL: url, Offset: idx[4]})
-			}
-		}
+	for _, idx := range quotedURLRegex.FindAllStringSubmatchIndex(content, -1) {
+		addAlt(idx, ContextQuoted)
 	}
 	// Bare URLs
-	if spans := bareURLRegex.FindAllStringIndex(content, -1); len(spans) > 0 {
-		for _, sp := range spans {
-			url := content[sp[0]:sp[1]]
-			out = append(out, matchCandidate{URL: url, Offset: sp[0]})
-		}
+	for _, sp := range bareURLRegex.FindAllStringIndex(content, -1) {
+		add(sp[0], sp[1], ContextBare)
+	}
+	// Bare mailto: links (links in markdown/href syntax are caught above)
+	for _, sp := range mailtoRegex.FindAllStringIndex(content, -1) {
+		add(sp[0], sp[1], ContextBare)
 	}
 	return out
 }
 
+var stylesheetRelRegex = regexp.MustCompile(`(?i)\brel\s*=\s*["']?[^"'>]*\bstylesheet\b`)
+
+// enclosingTag returns the lowercased name and full text of the HTML start
+// tag containing pos, or "" when pos is not inside one.
+func enclosingTag(content string, pos int) (name, tag string) {
+	start := strings.LastIndexByte(content[:pos], '<')
+	if start < 0 || strings.IndexByte(content[start:pos], '>') >= 0 {
+		return "", ""
+	}
+	end := strings.IndexByte(content[pos:], '>')
+	if end < 0 {
+		return "", ""
+	}
+	tag = content[start : pos+end+1]
+	i := 1
+	for i < len(tag) && (tag[i] >= 'a' && tag[i] <= 'z' || tag[i] >= 'A' && tag[i] <= 'Z' || tag[i] >= '0' && tag[i] <= '9') {
+		i++
+	}
+	return strings.ToLower(tag[1:i]), tag
+}
+
 func LoadGitIgnore(root string) *ignore.GitIgnore {
 	var lines []string
 	gi := filepath.Join(root, ".gitignore")
@@ -771,30 +799,42 @@ func LoadGitIgnore(root string) *ignore.GitIgnore {
 
 // .slinkignore support
 type slinkyIgnore struct {
-	IgnorePaths []string `json:"ignorePaths" optional:"true"`
-	IgnoreURLs  []string `json:"ignoreURLs" optional:"true"`
+	IgnorePaths     []string `json:"ignorePaths" optional:"true"`
+	IgnoreURLs      []string `json:"ignoreURLs" optional:"true"`
+	CheckServerURLs bool     `json:"checkServerURLs" optional:"true"`
+	IncludeKeys     []string `json:"includeKeys" optional:"true"`
+	IgnoreKeys      []string `json:"ignoreKeys" optional:"true"`
+}
+
+// extractOptions carries the .slinkignore settings that change how file
+// contents are parsed, as opposed to which files/URLs are skipped.
+type extractOptions struct {
+	checkServerURLs bool
+	keys            keyPathFilter
+}
+
+func loadExtractOptions(root string) extractOptions {
+	var cfg slinkyIgnore
+	if _, err := ReadSlinkyConfig(root, &cfg); err != nil {
+		return extractOptions{}
+	}
+	return extractOptions{
+		checkServerURLs: cfg.CheckServerURLs,
+		keys:            newKeyPathFilter(cfg.IncludeKeys, cfg.IgnoreKeys),
+	}
 }
 
 func LoadSlinkyIgnore(root string) (*ignore.GitIgnore, []string) {
-	cfgPath := findSlinkyConfig(root)
+	var cfg slinkyIgnore
+	cfgPath, err := ReadSlinkyConfig(root, &cfg)
 	if cfgPath == "" {
 		return nil, nil
 	}
-	b, err := os.ReadFile(cfgPath)
-	if err != nil || len(b) == 0 {
+	if err != nil {
+		// Emit a GitHub Actions warning so users see misconfigurations
+		fmt.Printf("::warning:: Failed to parse .slinkignore at %s: %v\n", cfgPath, err)
 		return nil, nil
 	}
-	var cfg slinkyIgnore
-	// First attempt strict JSON
-	if jerr := json.Unmarshal(b, &cfg); jerr != nil {
-		// Try a relaxed pass: strip trailing commas before ] or }
-		relaxed := regexp.MustCompile(`,\s*([}\]])`).ReplaceAll(b, []byte("$1"))
-		if jerr2 := json.Unmarshal(relaxed, &cfg); jerr2 != nil {
-			// Emit a GitHub Actions warning so users see misconfigurations
-			fmt.Printf("::warning:: Failed to parse .slinkignore at %s: %v\n", cfgPath, jerr)
-			return nil, nil
-		}
-	}
 	if isDebugEnv() {
 		fmt.Println("::debug:: Loaded .slinkignore")
 		fmt.Printf("::debug:: IgnorePaths: %v\n", cfg.IgnorePaths)
@@ -843,25 +883,16 @@ func LoadSlinkyIgnore(root string) (*ignore.GitIgnore, []string) {
 
 // LoadSlinkyIgnorePatterns loads and parses a .slinkignore file, returning ignore patterns and URL patterns
 func LoadSlinkyIgnorePatterns(root string) ([]string, []string) {
-	cfgPath := findSlinkyConfig(root)
+	var cfg slinkyIgnore
+	cfgPath, err := ReadSlinkyConfig(root, &cfg)
 	if cfgPath == "" {
 		return nil, nil
 	}
-	b, err := os.ReadFile(cfgPath)
-	if err != nil || len(b) == 0 {
+	if err != nil {
+		// Emit a GitHub Actions warning so users see misconfigurations
+		fmt.Printf("::warning:: Failed to parse .slinkignore at %s: %v\n", cfgPath, err)
 		return nil, nil
 	}
-	var cfg slinkyIgnore
-	// First attempt strict JSON
-	if jerr := json.Unmarshal(b, &cfg); jerr != nil {
-		// Try a relaxed pass: strip trailing commas before ] or }
-		relaxed := regexp.MustCompile(`,\s*([}\]])`).ReplaceAll(b, []byte("$1"))
-		if jerr2 := json.Unmarshal(relaxed, &cfg); jerr2 != nil {
-			// Emit a GitHub Actions warning so users see misconfigurations
-			fmt.Printf("::warning:: Failed to parse .slinkignore at %s: %v\n", cfgPath, jerr)
-			return nil, nil
-		}
-	}
 	if isDebugEnv() {
 		fmt.Println("::debug:: Loaded .slinkignore")
 		fmt.Printf("::debug:: IgnorePaths: %v\n", cfg.IgnorePaths)
@@ -905,7 +936,7 @@ func LoadSlinkyIgnorePatterns(root string) ([]string, []string) {
 }
 
 // CollectURLsV2 is the improved version with better pattern matching and directory skipping
-func CollectURLsV2(rootPath string, globs []string, respectGitignore bool, ignorePatterns []string, slURLPatterns []string) (map[string][]string, error) {
+func CollectURLsV2(rootPath string, globs []string, respectGitignore bool, ignorePatterns []string, slURLPatterns []string) (map[string][]Source, error) {
 	if strings.TrimSpace(rootPath) == "" {
 		rootPath = "."
 	}
@@ -919,13 +950,14 @@ func CollectURLsV2(rootPath string, globs []string, respectGitignore bool, ignor
 		ignorePatterns = append(ignorePatterns, "**/.git/**")
 	}
 	ignorePatterns = append(ignorePatterns, "**/.slinkignore")
+	opts := loadExtractOptions(cleanRoot)
 
 	if isDebugEnv() {
 		fmt.Printf("::debug:: Include patterns: %v\n", globs)
 		fmt.Printf("::debug:: Ignore patterns: %v\n", ignorePatterns)
 	}
 
-	urlToFiles := make(map[string]map[string]struct{})
+	sources := make(sourceSet)
 
 	// 2 MiB max file size to avoid huge/binary files
 	const maxSize = 2 * 1024 * 1024
@@ -1022,20 +1054,7 @@ func CollectURLsV2(rootPath string, globs []string, respectGitignore bool, ignor
 		}
 
 		// Extract URLs using the existing logic
-		matches := extractCandidateMatches(string(content))
-		for _, m := range matches {
-			u := sanitizeURLToken(m.URL)
-			if u == "" {
-				continue
-			}
-			if isURLIgnored(u, slURLPatterns) {
-				continue
-			}
-			if urlToFiles[u] == nil {
-				urlToFiles[u] = make(map[string]struct{})
-			}
-			urlToFiles[u][rel] = struct{}{}
-		}
+		collectFileSources(sources, path, rel, string(content), opts, slURLPatterns)
 
 		return nil
 	}
@@ -1052,22 +1071,12 @@ func CollectURLsV2(rootPath string, globs []string, respectGitignore bool, ignor
 		}
 	}
 
-	// Convert to final format
-	result := make(map[string][]string)
-	for url, files := range urlToFiles {
-		var fileList []string
-		for f := range files {
-			fileList = append(fileList, f)
-		}
-		sort.Strings(fileList)
-		result[url] = fileList
-	}
-
-	return result, nil
+	return sources.sorted(), nil
 }
 
-// findSlinkyConfig searches upward from root for a .slinkignore file
-func findSlinkyConfig(root string) string {
+// FindSlinkyConfig searches upward from root for a .slinkignore file and returns
+// its path, or "" if none exists.
+func FindSlinkyConfig(root string) string {
 	cur := root
 	for {
 		cfg := filepath.Join(cur, ".slinkignore")
@@ -1089,6 +1098,30 @@ func findSlinkyConfig(root string) string {
 	return ""
 }
 
+// ReadSlinkyConfig finds the nearest .slinkignore at or above root and decodes
+// it into v, tolerating trailing commas. It returns the file's path, or "" when
+// there is none; an empty file leaves v untouched.
+func ReadSlinkyConfig(root string, v any) (string, error) {
+	cfgPath := FindSlinkyConfig(root)
+	if cfgPath == "" {
+		return "", nil
+	}
+	b, err := os.ReadFile(cfgPath)
+	if err != nil || len(b) == 0 {
+		return cfgPath, nil
+	}
+	// First attempt strict JSON
+	if jerr := json.Unmarshal(b, v); jerr != nil {
+		// Try a relaxed pass: strip trailing commas before ] or }
+		if json.Unmarshal(trailingCommaRegex.ReplaceAll(b, []byte("$1")), v) != nil {
+			return cfgPath, jerr
+		}
+	}
+	return cfgPath, nil
+}
+
+var trailingCommaRegex = regexp.MustCompile(`,\s*([}\]])`)
+
 func isURLIgnored(u string, patterns []string) bool {
 	if len(patterns) == 0 {
 		return false
@@ -72,8 +72,8 @@ func TestCollectURLs_FromTestFiles(t *testing.T) {
 	// Verify .slinkignore path ignores: file under ignore-me should not contribute
 	for u, files := range urls {
 		for _, f := range files {
-			if strings.Contains(f, "ignore-me/") || strings.Contains(f, "node_modules/") || strings.HasSuffix(f, "package-lock.json") {
-				t.Fatalf("file %s should have been ignored via .slinkignore, but contributed to URL %s", f, u)
+			if strings.Contains(f.File, "ignore-me/") || strings.Contains(f.File, "node_modules/") || strings.HasSuffix(f.File, "package-lock.json") {
+				t.Fatalf("file %s should have been ignored via .slinkignore, but contributed to URL %s", f.File, u)
 			}
 		}
 	}
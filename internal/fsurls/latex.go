@@ -0,0 +1,114 @@
+package fsurls
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var texURLCommandRegex = regexp.MustCompile(`\\(url|href|doi)\s*\{`)
+var bibFieldRegex = regexp.MustCompile(`(?i)\b(url|doi)\s*=\s*([{"])`)
+var doiPrefixRegex = regexp.MustCompile(`(?i)^(?:doi:\s*|https?://(?:dx\.)?doi\.org/)`)
+
+// extractLaTeXMatches handles .tex and .bib files: \url{}, \href{url}{text},
+// \doi{} and BibTeX url/doi fields. Arguments are delimited by balanced
+// braces, LaTeX escapes (\%, \#, \_, \&) are undone, and DOIs are expanded to
+// https://doi.org/ URLs. ok=false means the file is not LaTeX/BibTeX.
+func extractLaTeXMatches(path string, content string) ([]matchCandidate, bool) {
+	ext := strings.ToLower(filepath.Ext(path))
+	switch ext {
+	case ".tex", ".ltx", ".sty", ".cls", ".bib":
+	default:
+		return nil, false
+	}
+	type span struct{ start, end int }
+	var spans []span
+	var out []matchCandidate
+	add := func(value string, start, end int, isDOI bool, extractor, context string) {
+		spans = append(spans, span{start, end})
+		trimmed := strings.TrimSpace(value)
+		u := unescapeLaTeX(trimmed)
+		if isDOI {
+			u = "https://doi.org/" + doiPrefixRegex.ReplaceAllString(u, "")
+		}
+		lead := len(value) - len(strings.TrimLeft(value, " \t\n"))
+		out = append(out, matchCandidate{URL: u, Offset: start + lead, Length: len(trimmed), Extractor: extractor, Context: context})
+	}
+
+	for _, idx := range texURLCommandRegex.FindAllStringSubmatchIndex(content, -1) {
+		open := idx[1] - 1
+		end := matchingBrace(content, open)
+		if end < 0 {
+			continue
+		}
+		cmd := content[idx[2]:idx[3]]
+		add(content[open+1:end], open+1, end, cmd == "doi", "latex", "latex-"+cmd)
+	}
+
+	if ext == ".bib" {
+		for _, idx := range bibFieldRegex.FindAllStringSubmatchIndex(content, -1) {
+			open := idx[4]
+			var end int
+			if content[open] == '{' {
+				end = matchingBrace(content, open)
+			} else {
+				end = strings.IndexByte(content[open+1:], '"')
+				if end >= 0 {
+					end += open + 1
+				}
+			}
+			if end < 0 {
+				continue
+			}
+			value := content[open+1 : end]
+			// url = {\url{...}} is handled by the command pass above
+			if strings.Contains(value, `\url`) || strings.Contains(value, `\href`) {
+				continue
+			}
+			field := strings.ToLower(content[idx[2]:idx[3]])
+			add(value, open+1, end, field == "doi", "bibtex", "bib-"+field)
+		}
+	}
+
+	// Bare URLs in prose and comments still count, except inside arguments
+	// already handled above where braces and escapes would confuse them.
+	for _, m := range extractCandidateMatches(content) {
+		inside := false
+		for _, sp := range spans {
+			if m.Offset >= sp.start && m.Offset < sp.end {
+				inside = true
+				break
+			}
+		}
+		if !inside {
+			out = append(out, m)
+		}
+	}
+	return out, true
+}
+
+// matchingBrace returns the index of the '}' closing the '{' at open, or -1.
+// Escaped braces (\{ and \}) do not count.
+func matchingBrace(content string, open int) int {
+	depth := 0
+	for i := open; i < len(content); i++ {
+		switch content[i] {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+var latexEscapeReplacer = strings.NewReplacer(`\%`, `%`, `\#`, `#`, `\_`, `_`, `\&`, `&`, `\~`, `~`, `\$`, `$`)
+
+func unescapeLaTeX(s string) string {
+	return latexEscapeReplacer.Replace(s)
+}
@@ -0,0 +1,38 @@
+package fsurls
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestCollectURLs_LaTeXAndBibTeX(t *testing.T) {
+	root := filepath.Join("..", "..", "testdata", "latex")
+	urls, err := CollectURLs(root, []string{"**/*"}, true)
+	if err != nil {
+		t.Fatalf("CollectURLs error: %v", err)
+	}
+
+	want := map[string]string{
+		"https://example.com/a_{b}":              "paper.tex|3|10",
+		"https://example.com/search?q=a%20b#top": "paper.tex|3|47",
+		"https://doi.org/10.1000/xyz123":         "paper.tex|4|18",
+		"https://example.com/dataset":            "paper.tex|4|50",
+		"https://example.com/knuth{84}":          "refs.bib|3|10",
+		"https://doi.org/10.1093/comjnl/27.2.97": "refs.bib|4|10",
+		"https://example.com/misc":               "refs.bib|7|24",
+		"https://example.com/quoted":             "refs.bib|8|10",
+		"https://doi.org/10.5555/12345":          "refs.bib|9|10",
+	}
+	for u, src := range want {
+		srcs, ok := urls[u]
+		if !ok {
+			t.Fatalf("expected URL %q to be collected; got %v", u, urls)
+		}
+		if srcs[0].String() != src {
+			t.Fatalf("URL %q: expected source %q, got %v", u, src, srcs)
+		}
+	}
+	if _, ok := urls[`https://example.com/search?q=a\%20b\#top`]; ok {
+		t.Fatalf("expected LaTeX escapes to be removed from \\href target")
+	}
+}
@@ -0,0 +1,185 @@
+package fsurls
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// reStructuredText
+var rstInlineLinkRegex = regexp.MustCompile("`[^`<]*<([^<>`\\s]+)>`__?")
+var rstTargetRegex = regexp.MustCompile(`(?m)^[ \t]*\.\. _[^:\n]*[^\\]:[ \t]+(\S+)[ \t]*$`)
+var rstDirectiveRegex = regexp.MustCompile(`(?m)^[ \t]*\.\. (?:image|figure|include|literalinclude|download)::[ \t]+(\S+)`)
+
+// AsciiDoc
+var adocMacroRegex = regexp.MustCompile(`\b(?:link|xref|image|include|video|audio):{1,2}(\+\+[^+\n]+\+\+|[^\s\[\]]+)\[`)
+var adocXrefRegex = regexp.MustCompile(`<<([^<>,\s]+)(?:,[^<>]*)?>>`)
+var adocImagesDirRegex = regexp.MustCompile(`(?m)^:imagesdir:[ \t]*(\S*)[ \t]*$`)
+
+// Org-mode
+var orgLinkRegex = regexp.MustCompile(`\[\[([^\[\]\n]+)\](?:\[[^\[\]]*\])?\]`)
+
+var schemeRegex = regexp.MustCompile(`^(?i)[a-z][a-z0-9+.\-]*:`)
+
+// extractMarkupMatches handles reStructuredText, AsciiDoc and Org-mode files.
+// Their explicit link syntaxes are parsed for exact target positions, and
+// relative targets become file:// URLs (keeping any #anchor) so the checker
+// can verify them on disk. ok=false means the file is not one of these formats.
+func extractMarkupMatches(docPath string, content string) ([]matchCandidate, bool) {
+	var out []matchCandidate
+	switch strings.ToLower(filepath.Ext(docPath)) {
+	case ".rst":
+		out = extractRSTMatches(docPath, content)
+	case ".adoc", ".asciidoc", ".asc":
+		out = extractAsciiDocMatches(docPath, content)
+	case ".org":
+		out = extractOrgMatches(docPath, content)
+	default:
+		return nil, false
+	}
+	// Bare URLs in prose still count
+	return append(out, extractCandidateMatches(content)...), true
+}
+
+func extractRSTMatches(docPath string, content string) []matchCandidate {
+	var out []matchCandidate
+	add := func(start, end int, context string) {
+		target := content[start:end]
+		// `text <name_>`_ refers to a named target, not a URL
+		if strings.HasSuffix(target, "_") && !strings.Contains(target, "/") {
+			return
+		}
+		target, ok := rstHTMLSource(docPath, target)
+		if !ok {
+			return
+		}
+		if u := markupTargetURL(docPath, target); u != "" {
+			out = append(out, matchCandidate{URL: u, Offset: start, Length: end - start, Extractor: "rst", Context: context})
+		}
+	}
+	for _, idx := range rstInlineLinkRegex.FindAllStringSubmatchIndex(content, -1) {
+		add(idx[2], idx[3], "rst-link")
+	}
+	for _, idx := range rstTargetRegex.FindAllStringSubmatchIndex(content, -1) {
+		add(idx[2], idx[3], "rst-target")
+	}
+	for _, idx := range rstDirectiveRegex.FindAllStringSubmatchIndex(content, -1) {
+		add(idx[2], idx[3], "rst-directive")
+	}
+	return out
+}
+
+// rstHTMLSource maps a relative link to a built page ("install.html#setup")
+// back to the .rst source Sphinx builds it from. ok is false when there is no
+// such source: the page comes from elsewhere and its output is not on disk.
+// Other targets are returned unchanged.
+func rstHTMLSource(docPath, target string) (string, bool) {
+	file, frag, hasFrag := strings.Cut(target, "#")
+	ext := strings.ToLower(path.Ext(file))
+	if schemeRegex.MatchString(target) || (ext != ".html" && ext != ".htm") {
+		return target, true
+	}
+	src := strings.TrimSuffix(file, path.Ext(file)) + ".rst"
+	if _, err := os.Stat(filepath.Join(filepath.Dir(docPath), filepath.FromSlash(src))); err != nil {
+		return "", false
+	}
+	if hasFrag {
+		src += "#" + frag
+	}
+	return src, true
+}
+
+func extractAsciiDocMatches(docPath string, content string) []matchCandidate {
+	var out []matchCandidate
+	imagesDir := ""
+	if m := adocImagesDirRegex.FindStringSubmatch(content); m != nil {
+		imagesDir = m[1]
+	}
+	for _, idx := range adocMacroRegex.FindAllStringSubmatchIndex(content, -1) {
+		start, end := idx[2], idx[3]
+		target := content[start:end]
+		if strings.HasPrefix(target, "++") {
+			start, end = start+2, end-2
+			target = content[start:end]
+		}
+		macro := content[idx[0] : strings.IndexByte(content[idx[0]:], ':')+idx[0]]
+		switch macro {
+		case "xref":
+			target = adocXrefTarget(target)
+		case "image", "video", "audio":
+			if imagesDir != "" && !schemeRegex.MatchString(target) && !strings.HasPrefix(target, "/") {
+				target = path.Join(imagesDir, target)
+			}
+		}
+		if u := markupTargetURL(docPath, target); u != "" {
+			out = append(out, matchCandidate{URL: u, Offset: start, Length: end - start, Extractor: "asciidoc", Context: "adoc-" + macro})
+		}
+	}
+	for _, idx := range adocXrefRegex.FindAllStringSubmatchIndex(content, -1) {
+		if u := markupTargetURL(docPath, adocXrefTarget(content[idx[2]:idx[3]])); u != "" {
+			out = append(out, matchCandidate{URL: u, Offset: idx[2], Length: idx[3] - idx[2], Extractor: "asciidoc", Context: "adoc-xref"})
+		}
+	}
+	return out
+}
+
+// adocXrefTarget normalizes an xref/<<>> target: a bare id refers to the
+// current document, and a document name without extension means .adoc.
+func adocXrefTarget(target string) string {
+	doc, id, hasID := strings.Cut(target, "#")
+	if !hasID {
+		if !strings.Contains(doc, ".") && !strings.Contains(doc, "/") {
+			return "#" + doc
+		}
+		return doc
+	}
+	if doc != "" && path.Ext(doc) == "" {
+		doc += ".adoc"
+	}
+	return doc + "#" + id
+}
+
+func extractOrgMatches(docPath string, content string) []matchCandidate {
+	var out []matchCandidate
+	for _, idx := range orgLinkRegex.FindAllStringSubmatchIndex(content, -1) {
+		start, end := idx[2], idx[3]
+		target := content[start:end]
+		if after, ok := strings.CutPrefix(target, "file:"); ok {
+			start += len("file:")
+			target = after
+		} else if !strings.HasPrefix(target, "./") && !strings.HasPrefix(target, "../") && !strings.HasPrefix(target, "/") && !schemeRegex.MatchString(target) {
+			// [[target]], [[*heading]] and [[#id]] search the current file
+			continue
+		}
+		// file:path::search — the search option is not a URL fragment
+		target, _, _ = strings.Cut(target, "::")
+		if u := markupTargetURL(docPath, target); u != "" {
+			out = append(out, matchCandidate{URL: u, Offset: start, Length: len(target), Extractor: "org", Context: "org-link"})
+		}
+	}
+	return out
+}
+
+// markupTargetURL maps a link target from a markup document to the URL to
+// check: web URLs as-is, relative paths (and #anchors, which refer to the
+// document itself) as file:// URLs. Other schemes are not handled here.
+func markupTargetURL(docPath, target string) string {
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return ""
+	}
+	if schemeRegex.MatchString(target) {
+		low := strings.ToLower(target)
+		if strings.HasPrefix(low, "http://") || strings.HasPrefix(low, "https://") || strings.HasPrefix(low, "mailto:") {
+			return target
+		}
+		return ""
+	}
+	if strings.ContainsAny(target, "{}") {
+		// Attribute references like {docs-url}/page cannot be resolved here
+		return ""
+	}
+	return localTargetURL(docPath, target)
+}
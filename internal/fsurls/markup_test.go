@@ -0,0 +1,87 @@
+package fsurls
+
+import (
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCollectURLs_MarkupFormats(t *testing.T) {
+	root := filepath.Join("..", "..", "testdata", "markup")
+	urls, err := CollectURLs(root, []string{"**/*"}, true)
+	if err != nil {
+		t.Fatalf("CollectURLs error: %v", err)
+	}
+
+	web := map[string]string{
+		"https://docs.python.org/3/":    "guide.rst|4|23",
+		"https://www.sphinx-doc.org/":   "guide.rst|7|13",
+		"https://asciidoctor.org/docs/": "manual.adoc|7|11",
+		"https://orgmode.org/manual/":   "notes.org|2|9",
+	}
+	for u, src := range web {
+		srcs, ok := urls[u]
+		if !ok {
+			t.Fatalf("expected URL %q to be collected", u)
+		}
+		if srcs[0].String() != src {
+			t.Fatalf("URL %q: expected source %q, got %v", u, src, srcs)
+		}
+	}
+
+	local := map[string]bool{}
+	for u := range urls {
+		if pu, err := url.Parse(u); err == nil && pu.Scheme == "file" {
+			target := path.Base(pu.Opaque)
+			if pu.Fragment != "" {
+				target += "#" + pu.Fragment
+			}
+			local[target] = true
+		}
+	}
+	for _, want := range []string{
+		"manual.adoc", "missing-install.rst", "logo.txt",
+		"guide.rst", "manual.adoc#setup", "manual.adoc#_getting_started", "manual.adoc#nowhere", "other.adoc#intro",
+		"missing.org",
+	} {
+		if !local[want] {
+			t.Fatalf("expected local target %q; got %v", want, local)
+		}
+	}
+	for target := range local {
+		if strings.Contains(target, "Notes") || strings.Contains(target, "custom") {
+			t.Fatalf("did not expect Org internal link %q to be collected", target)
+		}
+	}
+}
+
+func TestExtractAsciiDocMatches_MacroNeedsBoundary(t *testing.T) {
+	for _, m := range extractAsciiDocMatches("doc.adoc", "See xlink:foo[bar] and mylink:other.adoc[x].\n") {
+		t.Fatalf("unexpected match %q in a longer word", m.URL)
+	}
+}
+
+func TestExtractRSTMatches_HTMLTargets(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"index.rst", "install.rst"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	content := "See `install <install.html#setup>`_ and `api <api/index.html>`_.\n"
+	var got []string
+	for _, m := range extractRSTMatches(filepath.Join(dir, "index.rst"), content) {
+		u, err := url.Parse(m.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, path.Base(u.Opaque)+"#"+u.Fragment)
+	}
+	// The built page maps to its source; output without a source is skipped
+	if strings.Join(got, " ") != "install.rst#setup" {
+		t.Fatalf("got %v, want only install.rst#setup", got)
+	}
+}
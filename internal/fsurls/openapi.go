@@ -0,0 +1,210 @@
+package fsurls
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// extractOpenAPIMatches handles OpenAPI/Swagger/AsyncAPI documents. It returns
+// ok=false when the file is not such a document so the caller can fall back to
+// the generic extractor.
+//
+// Server URLs (servers[].url) are usually templates or API bases that don't
+// resolve to a page, so they are dropped unless checkServerURLs is set.
+// externalDocs.url, termsOfService, contact.url and license.url are always
+// collected. $ref targets are collected as well: remote refs as-is, relative
+// refs as file: URLs (JSON pointer fragment kept) so the checker can verify
+// that the file exists and the pointer resolves.
+func extractOpenAPIMatches(path string, content string, opts extractOptions) ([]matchCandidate, bool) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json", ".yaml", ".yml":
+	default:
+		return nil, false
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &doc); err != nil || len(doc.Content) == 0 {
+		return nil, false
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode || !isAPISpecRoot(root) {
+		return nil, false
+	}
+
+	lineStarts := computeLineStarts(content)
+	type span struct{ start, end int }
+	var serverSpans []span
+	var out []matchCandidate
+
+	walkYAMLMappings(root, func(key string, parentKey string, val *yaml.Node) {
+		switch {
+		case key == "servers":
+			for _, srv := range serverNodes(val) {
+				for i := 0; i+1 < len(srv.Content); i += 2 {
+					if srv.Content[i].Value != "url" || srv.Content[i+1].Kind != yaml.ScalarNode {
+						continue
+					}
+					v := srv.Content[i+1]
+					off := yamlValueOffset(content, lineStarts, v)
+					serverSpans = append(serverSpans, span{off, off + len(v.Value)})
+				}
+			}
+		case key == "$ref" && val.Kind == yaml.ScalarNode:
+			if u := refTargetURL(path, val.Value); u != "" {
+				out = append(out, matchCandidate{URL: u, Offset: yamlValueOffset(content, lineStarts, val), Length: len(val.Value), Extractor: "openapi", Context: ContextRef})
+			}
+		case val.Kind == yaml.ScalarNode && isSpecLinkField(parentKey, key):
+			out = append(out, matchCandidate{URL: val.Value, Offset: yamlValueOffset(content, lineStarts, val), Extractor: "openapi", Context: "spec-" + key})
+		}
+	})
+
+	for _, m := range extractCandidateMatches(content) {
+		inServer := false
+		for _, sp := range serverSpans {
+			if m.Offset >= sp.start && m.Offset < sp.end {
+				inServer = true
+				break
+			}
+		}
+		if inServer && !opts.checkServerURLs {
+			continue
+		}
+		out = append(out, m)
+	}
+	return out, true
+}
+
+func isAPISpecRoot(root *yaml.Node) bool {
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		switch root.Content[i].Value {
+		case "openapi", "swagger", "asyncapi":
+			return true
+		}
+	}
+	return false
+}
+
+// isSpecLinkField reports whether key (inside parentKey) is one of the
+// documentation links every OpenAPI/AsyncAPI tool renders.
+func isSpecLinkField(parentKey, key string) bool {
+	switch {
+	case key == "termsOfService":
+		return true
+	case key == "url" && (parentKey == "externalDocs" || parentKey == "contact" || parentKey == "license"):
+		return true
+	}
+	return false
+}
+
+// serverNodes returns the server objects of a servers value: a sequence in
+// OpenAPI 3, a name -> server mapping in AsyncAPI.
+func serverNodes(val *yaml.Node) []*yaml.Node {
+	var out []*yaml.Node
+	switch val.Kind {
+	case yaml.SequenceNode:
+		for _, n := range val.Content {
+			if n.Kind == yaml.MappingNode {
+				out = append(out, n)
+			}
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(val.Content); i += 2 {
+			if n := val.Content[i+1]; n.Kind == yaml.MappingNode {
+				out = append(out, n)
+			}
+		}
+	}
+	return out
+}
+
+// refTargetURL maps a $ref value to the URL to check. Document-local refs
+// ("#/components/...") are left to spec validators and return "".
+func refTargetURL(specPath, ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" || strings.HasPrefix(ref, "#") {
+		return ""
+	}
+	low := strings.ToLower(ref)
+	if strings.HasPrefix(low, "http://") || strings.HasPrefix(low, "https://") {
+		return ref
+	}
+	return localTargetURL(specPath, ref)
+}
+
+// localTargetURL resolves a relative link target against the directory of the
+// document it appears in and returns it as a file: URL, keeping the fragment.
+// The path is relative to the working directory, like Source.File, so reports
+// don't carry the local checkout's location. An empty path ("#anchor") refers
+// to the document itself.
+func localTargetURL(docPath, target string) string {
+	file, frag, _ := strings.Cut(target, "#")
+	if unescaped, err := url.PathUnescape(file); err == nil {
+		file = unescaped
+	}
+	// url.URL escapes the fragment again when formatting
+	if unescaped, err := url.PathUnescape(frag); err == nil {
+		frag = unescaped
+	}
+	p := docPath
+	if file != "" {
+		p = filepath.Join(filepath.Dir(docPath), filepath.FromSlash(file))
+	}
+	if filepath.IsAbs(p) {
+		if wd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(wd, p); err == nil {
+				p = rel
+			}
+		}
+	}
+	p = filepath.ToSlash(filepath.Clean(p))
+	if strings.HasPrefix(p, "/") {
+		return (&url.URL{Scheme: "file", Path: p, Fragment: frag}).String()
+	}
+	return (&url.URL{Scheme: "file", Opaque: (&url.URL{Path: p}).EscapedPath(), Fragment: frag}).String()
+}
+
+// walkYAMLMappings calls fn for every key/value pair in the tree, along with
+// the key under which the enclosing mapping lives.
+func walkYAMLMappings(n *yaml.Node, fn func(key string, parentKey string, val *yaml.Node)) {
+	var walk func(n *yaml.Node, parentKey string)
+	walk = func(n *yaml.Node, parentKey string) {
+		switch n.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				k, v := n.Content[i].Value, n.Content[i+1]
+				fn(k, parentKey, v)
+				walk(v, k)
+			}
+		case yaml.SequenceNode:
+			for _, c := range n.Content {
+				walk(c, parentKey)
+			}
+		}
+	}
+	walk(n, "")
+}
+
+// computeLineStarts returns the byte offset at which each line begins.
+func computeLineStarts(content string) []int {
+	starts := []int{0}
+	for i := 0; i < len(content); i++ {
+		if content[i] == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	return starts
+}
+
+// yamlValueOffset maps a scalar node back to the byte offset of its text.
+// yaml.v3 reports 1-based line/column of the token (including any quote), so
+// we search forward from there for the value itself.
+func yamlValueOffset(content string, lineStarts []int, n *yaml.Node) int {
+	start := yamlNodeOffset(content, lineStarts, n)
+	if idx := strings.Index(content[start:], n.Value); idx >= 0 {
+		return start + idx
+	}
+	return start
+}
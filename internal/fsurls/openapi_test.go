@@ -0,0 +1,68 @@
+package fsurls
+
+import (
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+)
+
+func TestCollectURLs_OpenAPI(t *testing.T) {
+	root := filepath.Join("..", "..", "testdata", "openapi")
+	urls, err := CollectURLs(root, []string{"**/*"}, true)
+	if err != nil {
+		t.Fatalf("CollectURLs error: %v", err)
+	}
+
+	for _, u := range []string{
+		"https://example.com/terms",
+		"https://example.com/support",
+		"https://opensource.org/licenses/MIT",
+		"https://example.com/docs",
+	} {
+		if _, ok := urls[u]; !ok {
+			t.Fatalf("expected spec link %q to be collected", u)
+		}
+	}
+
+	if _, ok := urls["https://petstore.internal.example.com/v1"]; ok {
+		t.Fatalf("expected servers[].url to be skipped by default")
+	}
+
+	refs := map[string]bool{}
+	for u := range urls {
+		if pu, err := url.Parse(u); err == nil && pu.Scheme == "file" {
+			refs[path.Base(pu.Opaque)+"#"+pu.Fragment] = true
+		}
+	}
+	for _, want := range []string{"schemas.yaml#/Pet", "schemas.yaml#/Missing", "errors.yaml#/Error"} {
+		if !refs[want] {
+			t.Fatalf("expected $ref target %q to be collected; got %v", want, refs)
+		}
+	}
+}
+
+func TestLocalTargetURL_EscapedFragment(t *testing.T) {
+	// RFC 6901 URI fragment form of the pointer /paths/~1pets~1{id}
+	raw := localTargetURL(filepath.Join("spec", "openapi.yaml"), "other%20spec.json#/paths/~1pets~1%7Bid%7D")
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatalf("parse %q: %v", raw, err)
+	}
+	if u.Opaque != "spec/other%20spec.json" || u.Fragment != "/paths/~1pets~1{id}" {
+		t.Fatalf("got path %q fragment %q from %q", u.Opaque, u.Fragment, raw)
+	}
+}
+
+func TestLocalTargetURL_RelativeToWorkingDir(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// An absolute scan root must not leak into the reported URL
+	raw := localTargetURL(filepath.Join(wd, "spec", "openapi.yaml"), "../schemas.yaml#/Pet")
+	if raw != "file:schemas.yaml#/Pet" {
+		t.Fatalf("got %q, want file:schemas.yaml#/Pet", raw)
+	}
+}
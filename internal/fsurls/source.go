@@ -0,0 +1,134 @@
+package fsurls
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Syntactic contexts a URL can be found in. Extractors for specific formats
+// may report their own (e.g. "rst-target", "bib-doi").
+const (
+	ContextMarkdownLink  = "markdown-link"
+	ContextMarkdownImage = "markdown-image"
+	ContextHref          = "href"
+	ContextSrc           = "src"
+	ContextImgSrc        = "img-src"
+	ContextScriptSrc     = "script-src"
+	ContextStylesheet    = "stylesheet"
+	ContextAutolink      = "autolink"
+	ContextQuoted        = "quoted"
+	ContextBare          = "bare"
+	ContextRef           = "ref"
+)
+
+// Source is one occurrence of a URL in a scanned file. Lines and columns are
+// 1-based; End* point just past the last character, and offsets are byte
+// offsets into the file, so content[StartOffset:EndOffset] is exactly the text
+// that was matched.
+type Source struct {
+	File        string `json:"file"`
+	StartLine   int    `json:"startLine"`
+	StartColumn int    `json:"startColumn"`
+	EndLine     int    `json:"endLine"`
+	EndColumn   int    `json:"endColumn"`
+	StartOffset int    `json:"startOffset"`
+	EndOffset   int    `json:"endOffset"`
+	Extractor   string `json:"extractor"`
+	Context     string `json:"context"`
+	KeyPath     string `json:"keyPath,omitempty"`
+}
+
+// String renders the source in the compact path|line|col[|keypath] form.
+func (s Source) String() string {
+	out := fmt.Sprintf("%s|%d|%d", s.File, s.StartLine, s.StartColumn)
+	if s.KeyPath != "" {
+		out += "|" + s.KeyPath
+	}
+	return out
+}
+
+// SortSources orders sources by file and position.
+func SortSources(srcs []Source) {
+	sort.Slice(srcs, func(i, j int) bool {
+		if srcs[i].File != srcs[j].File {
+			return srcs[i].File < srcs[j].File
+		}
+		return srcs[i].StartOffset < srcs[j].StartOffset
+	})
+}
+
+// sourceSet accumulates URL -> sources, keeping one entry per file position
+// (the first extractor to report a position wins).
+type sourceSet map[string]map[string]Source
+
+func (ss sourceSet) add(u string, s Source) {
+	set, ok := ss[u]
+	if !ok {
+		set = make(map[string]Source)
+		ss[u] = set
+	}
+	key := fmt.Sprintf("%s|%d", s.File, s.StartOffset)
+	if _, exists := set[key]; !exists {
+		set[key] = s
+	}
+}
+
+func (ss sourceSet) sorted() map[string][]Source {
+	result := make(map[string][]Source, len(ss))
+	for u, set := range ss {
+		list := make([]Source, 0, len(set))
+		for _, s := range set {
+			list = append(list, s)
+		}
+		SortSources(list)
+		result[u] = list
+	}
+	return result
+}
+
+// collectFileSources runs the extractors over one file's content and adds every
+// accepted URL, with its precise span, to ss.
+func collectFileSources(ss sourceSet, path string, rel string, content string, opts extractOptions, slURLPatterns []string) {
+	for _, m := range extractFileMatches(path, content, opts) {
+		u := sanitizeURLToken(m.URL)
+		if u == "" {
+			continue
+		}
+		if isURLIgnored(u, slURLPatterns) {
+			continue
+		}
+		start, end := m.Offset, m.Offset+m.length()
+		if end > len(content) {
+			end = len(content)
+		}
+		// Narrow the span to the sanitized URL when it appears verbatim
+		if i := indexIn(content, start, end, u); i >= 0 {
+			start, end = i, i+len(u)
+		}
+		startLine, startCol := computeLineCol(content, start)
+		endLine, endCol := computeLineCol(content, end)
+		ss.add(u, Source{
+			File:        rel,
+			StartLine:   startLine,
+			StartColumn: startCol,
+			EndLine:     endLine,
+			EndColumn:   endCol,
+			StartOffset: start,
+			EndOffset:   end,
+			Extractor:   m.Extractor,
+			Context:     m.Context,
+			KeyPath:     m.KeyPath,
+		})
+	}
+}
+
+func indexIn(content string, start, end int, s string) int {
+	if start < 0 || start > end || end > len(content) {
+		return -1
+	}
+	if i := strings.Index(content[start:end], s); i >= 0 {
+		return start + i
+	}
+	return -1
+}
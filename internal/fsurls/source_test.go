@@ -0,0 +1,102 @@
+package fsurls
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCollectURLs_SourceSpans(t *testing.T) {
+	dir := t.TempDir()
+	content := "# Title\n" +
+		"See [docs](https://example.com/docs) and ![logo](https://example.com/logo.png).\n" +
+		"<a href=\"https://example.com/page\">x</a> plus https://example.com/bare.\n" +
+		"Write to [us](mailto:team@example.com) or mailto:help@example.com.\n"
+	file := filepath.Join(dir, "doc.md")
+	if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	urls, err := CollectURLs(dir, []string{"**/*"}, false)
+	if err != nil {
+		t.Fatalf("CollectURLs error: %v", err)
+	}
+
+	want := map[string]string{
+		"https://example.com/docs":     ContextMarkdownLink,
+		"https://example.com/logo.png": ContextMarkdownImage,
+		"https://example.com/page":     ContextHref,
+		"https://example.com/bare":     ContextBare,
+		"mailto:team@example.com":      ContextMarkdownLink,
+		"mailto:help@example.com":      ContextBare,
+	}
+	for u, context := range want {
+		srcs := urls[u]
+		if len(srcs) != 1 {
+			t.Fatalf("URL %q: expected exactly one source, got %v", u, srcs)
+		}
+		s := srcs[0]
+		if got := content[s.StartOffset:s.EndOffset]; got != u {
+			t.Fatalf("URL %q: span covers %q", u, got)
+		}
+		if s.Context != context || s.Extractor != "generic" {
+			t.Fatalf("URL %q: expected context %q from generic extractor, got %q/%q", u, context, s.Context, s.Extractor)
+		}
+		if s.EndLine != s.StartLine || s.EndColumn-s.StartColumn != len(u) {
+			t.Fatalf("URL %q: inconsistent span %+v", u, s)
+		}
+	}
+	if s := urls["https://example.com/docs"][0]; s.File != "doc.md" || s.StartLine != 2 || s.StartColumn != 12 {
+		t.Fatalf("unexpected position for docs link: %+v", s)
+	}
+}
+
+func TestCollectURLs_OtherSchemes(t *testing.T) {
+	dir := t.TempDir()
+	content := "Mirror: ftp://ftp.example.com/pub/file.tar.gz\n" +
+		"<img src=\"data:image/png;base64,iVBORw0KGgo=\">\n" +
+		"[open](vscode://file/tmp/x) but not <a href=\"javascript:void(0)\">this</a>\n"
+	if err := os.WriteFile(filepath.Join(dir, "doc.md"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	urls, err := CollectURLs(dir, []string{"**/*"}, false)
+	if err != nil {
+		t.Fatalf("CollectURLs error: %v", err)
+	}
+	for _, u := range []string{"ftp://ftp.example.com/pub/file.tar.gz", "data:image/png;base64,iVBORw0KGgo=", "vscode://file/tmp/x"} {
+		if len(urls[u]) != 1 {
+			t.Fatalf("expected %q once, got %v (all: %v)", u, urls[u], urls)
+		}
+	}
+	if len(urls) != 3 {
+		t.Fatalf("expected 3 URLs, got %v", urls)
+	}
+}
+
+func TestCollectURLs_TagContexts(t *testing.T) {
+	dir := t.TempDir()
+	content := "<img alt=\"logo\" src=\"https://example.com/logo.png\">\n" +
+		"<script type=\"module\" src=\"https://example.com/app.js\"></script>\n" +
+		"<link rel=\"preload stylesheet\" href=\"https://example.com/site.css\">\n" +
+		"<link rel=\"icon\" href=\"https://example.com/favicon.ico\">\n" +
+		"<iframe src=\"https://example.com/embed\"></iframe>\n"
+	if err := os.WriteFile(filepath.Join(dir, "page.html"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	urls, err := CollectURLs(dir, []string{"**/*"}, false)
+	if err != nil {
+		t.Fatalf("CollectURLs error: %v", err)
+	}
+	want := map[string]string{
+		"https://example.com/logo.png":    ContextImgSrc,
+		"https://example.com/app.js":      ContextScriptSrc,
+		"https://example.com/site.css":    ContextStylesheet,
+		"https://example.com/favicon.ico": ContextHref,
+		"https://example.com/embed":       ContextSrc,
+	}
+	for u, context := range want {
+		srcs := urls[u]
+		if len(srcs) != 1 || srcs[0].Context != context {
+			t.Errorf("URL %q: sources %+v, want context %q", u, srcs, context)
+		}
+	}
+}
@@ -0,0 +1,384 @@
+package fsurls
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// keyPathEntry records the byte span [Offset, End) of a value in a structured
+// file and the logical key path leading to it (e.g. "info.contact.url").
+type keyPathEntry struct {
+	Offset int
+	End    int
+	Path   string
+}
+
+// isStructuredFile reports whether path is a data/config format whose URLs
+// are located by key path rather than just line/column.
+func isStructuredFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json", ".yaml", ".yml", ".toml":
+		return true
+	}
+	return false
+}
+
+// annotateKeyPaths fills KeyPath on every match that falls inside a value of
+// a structured file. Files that fail to parse are left unannotated.
+func annotateKeyPaths(path string, content string, matches []matchCandidate) {
+	if len(matches) == 0 || !isStructuredFile(path) {
+		return
+	}
+	var entries []keyPathEntry
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		entries = tomlKeyPaths(content)
+	} else {
+		entries = yamlKeyPaths(content)
+	}
+	if len(entries) == 0 {
+		return
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Offset < entries[j].Offset })
+	for i := range matches {
+		// Last value starting at or before the match; URLs in comments or keys
+		// fall outside its span and get no key path
+		idx := sort.Search(len(entries), func(k int) bool { return entries[k].Offset > matches[i].Offset }) - 1
+		if idx >= 0 && matches[i].Offset < entries[idx].End {
+			matches[i].KeyPath = entries[idx].Path
+		}
+	}
+}
+
+// yamlKeyPaths parses YAML or JSON (a YAML subset) and returns the start of
+// every scalar value with its key path.
+func yamlKeyPaths(content string) []keyPathEntry {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &doc); err != nil || len(doc.Content) == 0 {
+		return nil
+	}
+	lineStarts := computeLineStarts(content)
+	var out []keyPathEntry
+	var walk func(n *yaml.Node, p string)
+	walk = func(n *yaml.Node, p string) {
+		switch n.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				walk(n.Content[i+1], joinKeyPath(p, n.Content[i].Value))
+			}
+		case yaml.SequenceNode:
+			for i, c := range n.Content {
+				walk(c, fmt.Sprintf("%s[%d]", p, i))
+			}
+		case yaml.ScalarNode:
+			if n.Line < 1 || n.Line > len(lineStarts) {
+				return
+			}
+			off := yamlNodeOffset(content, lineStarts, n)
+			out = append(out, keyPathEntry{Offset: off, End: yamlScalarEnd(content, off, n), Path: p})
+		}
+	}
+	for _, d := range doc.Content {
+		walk(d, "")
+	}
+	return out
+}
+
+// yamlNodeOffset converts a node's 1-based line and rune column to a byte
+// offset, skipping any anchor or tag in front of the value.
+func yamlNodeOffset(content string, lineStarts []int, n *yaml.Node) int {
+	if n.Line < 1 || n.Line > len(lineStarts) {
+		return 0
+	}
+	off := lineStarts[n.Line-1]
+	for col := 1; col < n.Column && off < len(content) && content[off] != '\n'; col++ {
+		_, size := utf8.DecodeRuneInString(content[off:])
+		off += size
+	}
+	for off < len(content) && (content[off] == '&' || content[off] == '!') {
+		end := off + strings.IndexAny(content[off:]+" ", " \t\n")
+		off = end
+		for off < len(content) && (content[off] == ' ' || content[off] == '\t') {
+			off++
+		}
+	}
+	return off
+}
+
+// yamlScalarEnd returns the byte offset just past the scalar starting at off.
+func yamlScalarEnd(content string, off int, n *yaml.Node) int {
+	if off >= len(content) {
+		return len(content)
+	}
+	switch {
+	case n.Style&yaml.DoubleQuotedStyle != 0:
+		for i := off + 1; i < len(content); i++ {
+			if content[i] == '\\' {
+				i++
+			} else if content[i] == '"' {
+				return i + 1
+			}
+		}
+		return len(content)
+	case n.Style&yaml.SingleQuotedStyle != 0:
+		for i := off + 1; i < len(content); i++ {
+			if content[i] == '\'' {
+				if i+1 < len(content) && content[i+1] == '\'' {
+					i++
+					continue
+				}
+				return i + 1
+			}
+		}
+		return len(content)
+	case n.Style&(yaml.LiteralStyle|yaml.FoldedStyle) == 0 && !strings.Contains(n.Value, "\n"):
+		// A single-line plain scalar is its own source text
+		if end := off + len(n.Value); end <= len(content) {
+			return end
+		}
+		return len(content)
+	}
+	// Block scalars (and multi-line plain ones) run over the following lines
+	// that are blank or indented deeper than the line they start on
+	lineStart := strings.LastIndexByte(content[:off], '\n') + 1
+	indent := len(content[lineStart:]) - len(strings.TrimLeft(content[lineStart:], " "))
+	end := strings.IndexByte(content[off:], '\n')
+	if end < 0 {
+		return len(content)
+	}
+	end += off
+	for end < len(content) {
+		next := strings.IndexByte(content[end+1:], '\n')
+		line := content[end+1:]
+		if next >= 0 {
+			line = line[:next]
+		}
+		if strings.TrimSpace(line) != "" && len(line)-len(strings.TrimLeft(line, " ")) <= indent {
+			break
+		}
+		if next < 0 {
+			return len(content)
+		}
+		end += 1 + next
+	}
+	return end
+}
+
+var tomlTableRegex = regexp.MustCompile(`^\s*(\[\[?)\s*([^\]]+?)\s*\]\]?\s*(#.*)?$`)
+var tomlKeyValueRegex = regexp.MustCompile(`^\s*((?:"[^"]*"|'[^']*'|[A-Za-z0-9_.\- ]+?))\s*=\s*`)
+var tomlStringRegex = regexp.MustCompile(`"(?:[^"\\]|\\.)*"|'[^']*'`)
+
+// tomlKeyPaths is a line-oriented TOML scanner covering tables, arrays of
+// tables, dotted keys, inline/multi-line arrays and multi-line strings. It
+// does not validate the document; it only tracks where each value lives.
+func tomlKeyPaths(content string) []keyPathEntry {
+	var out []keyPathEntry
+	table := ""
+	arrayCounts := make(map[string]int)
+	// Open multi-line array: key path and next index
+	arrayKey := ""
+	arrayIdx := 0
+	inMultiline := ""
+	offset := 0
+	for _, line := range strings.SplitAfter(content, "\n") {
+		lineStart := offset
+		offset += len(line)
+		trimmed := strings.TrimSpace(line)
+		if inMultiline != "" {
+			// The open multi-line string is the last entry
+			if i := strings.Index(line, inMultiline); i >= 0 {
+				inMultiline = ""
+				out[len(out)-1].End = lineStart + i + 3
+			} else {
+				out[len(out)-1].End = offset
+			}
+			continue
+		}
+		if arrayKey != "" {
+			for _, loc := range tomlStringRegex.FindAllStringIndex(line, -1) {
+				out = append(out, keyPathEntry{Offset: lineStart + loc[0], End: lineStart + loc[1], Path: fmt.Sprintf("%s[%d]", arrayKey, arrayIdx)})
+				arrayIdx++
+			}
+			if strings.Contains(stripTOMLStrings(line), "]") {
+				arrayKey = ""
+			}
+			continue
+		}
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if m := tomlTableRegex.FindStringSubmatch(line); m != nil && !strings.Contains(line, "=") {
+			name := tomlDottedPath("", m[2])
+			if m[1] == "[[" {
+				idx := arrayCounts[name]
+				arrayCounts[name] = idx + 1
+				table = fmt.Sprintf("%s[%d]", name, idx)
+			} else {
+				table = name
+			}
+			continue
+		}
+		loc := tomlKeyValueRegex.FindStringSubmatchIndex(line)
+		if loc == nil {
+			continue
+		}
+		key := tomlDottedPath(table, line[loc[2]:loc[3]])
+		rest := line[loc[1]:]
+		valueStart := lineStart + loc[1]
+		switch {
+		case strings.HasPrefix(rest, `"""`) || strings.HasPrefix(rest, `'''`):
+			delim := rest[:3]
+			end := lineStart + len(line)
+			if i := strings.Index(rest[3:], delim); i >= 0 {
+				end = valueStart + 3 + i + 3
+			} else {
+				inMultiline = delim
+			}
+			out = append(out, keyPathEntry{Offset: valueStart, End: end, Path: key})
+		case strings.HasPrefix(rest, "["):
+			idx := 0
+			for _, sloc := range tomlStringRegex.FindAllStringIndex(rest, -1) {
+				out = append(out, keyPathEntry{Offset: valueStart + sloc[0], End: valueStart + sloc[1], Path: fmt.Sprintf("%s[%d]", key, idx)})
+				idx++
+			}
+			if !strings.Contains(stripTOMLStrings(rest), "]") {
+				arrayKey, arrayIdx = key, idx
+			}
+		default:
+			end := valueStart + len(stripTOMLComment(rest))
+			if loc := tomlStringRegex.FindStringIndex(rest); loc != nil && loc[0] == 0 {
+				end = valueStart + loc[1]
+			}
+			out = append(out, keyPathEntry{Offset: valueStart, End: end, Path: key})
+		}
+	}
+	return out
+}
+
+// tomlDottedPath appends a TOML key or table name (a."b.c".d) to base.
+func tomlDottedPath(base string, raw string) string {
+	p := base
+	for raw = strings.TrimSpace(raw); raw != ""; {
+		var part string
+		if raw[0] == '"' || raw[0] == '\'' {
+			end := strings.IndexByte(raw[1:], raw[0])
+			if end < 0 {
+				part, raw = raw[1:], ""
+			} else {
+				part, raw = raw[1:end+1], raw[end+2:]
+			}
+		} else if dot := strings.IndexByte(raw, '.'); dot >= 0 {
+			part, raw = raw[:dot], raw[dot:]
+		} else {
+			part, raw = raw, ""
+		}
+		p = joinKeyPath(p, strings.TrimSpace(part))
+		raw = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(raw), "."))
+	}
+	return p
+}
+
+// stripTOMLComment drops a trailing "# ..." comment outside strings, along
+// with trailing whitespace.
+func stripTOMLComment(s string) string {
+	masked := []byte(s)
+	for _, loc := range tomlStringRegex.FindAllStringIndex(s, -1) {
+		for i := loc[0]; i < loc[1]; i++ {
+			masked[i] = 'x'
+		}
+	}
+	if i := strings.IndexByte(string(masked), '#'); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimRight(s, " \t\r\n")
+}
+
+func stripTOMLStrings(s string) string {
+	return tomlStringRegex.ReplaceAllString(s, "")
+}
+
+// joinKeyPath appends key to parent, bracket-quoting keys that would be
+// ambiguous in dotted form.
+func joinKeyPath(parent, key string) string {
+	if strings.ContainsAny(key, ".[]\" \t") || key == "" {
+		return parent + fmt.Sprintf("[%q]", key)
+	}
+	if parent == "" {
+		return key
+	}
+	return parent + "." + key
+}
+
+// compileKeyPathPattern turns a key path pattern into a regex. "*" matches one
+// key, "[*]" any index, "**" anything. A pattern also matches everything below
+// the path it names, so "servers" covers "servers[0].url".
+func compileKeyPathPattern(p string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(p); {
+		switch {
+		case strings.HasPrefix(p[i:], "**"):
+			b.WriteString(".*")
+			i += 2
+		case strings.HasPrefix(p[i:], "[*]"):
+			b.WriteString(`\[\d+\]`)
+			i += 3
+		case p[i] == '*':
+			b.WriteString(`[^.\[]+`)
+			i++
+		default:
+			b.WriteString(regexp.QuoteMeta(p[i : i+1]))
+			i++
+		}
+	}
+	b.WriteString(`(?:$|[.\[])`)
+	return regexp.Compile(b.String())
+}
+
+// keyPathFilter applies .slinkignore includeKeys/ignoreKeys to key paths.
+type keyPathFilter struct {
+	include []*regexp.Regexp
+	ignore  []*regexp.Regexp
+}
+
+func newKeyPathFilter(include, ignoreKeys []string) keyPathFilter {
+	var f keyPathFilter
+	for _, p := range include {
+		if re, err := compileKeyPathPattern(strings.TrimSpace(p)); err == nil && strings.TrimSpace(p) != "" {
+			f.include = append(f.include, re)
+		}
+	}
+	for _, p := range ignoreKeys {
+		if re, err := compileKeyPathPattern(strings.TrimSpace(p)); err == nil && strings.TrimSpace(p) != "" {
+			f.ignore = append(f.ignore, re)
+		}
+	}
+	return f
+}
+
+// Skip reports whether a URL found at keyPath should be dropped. URLs outside
+// structured values (keyPath == "") are never filtered here.
+func (f keyPathFilter) Skip(keyPath string) bool {
+	if keyPath == "" {
+		return false
+	}
+	for _, re := range f.ignore {
+		if re.MatchString(keyPath) {
+			return true
+		}
+	}
+	if len(f.include) == 0 {
+		return false
+	}
+	for _, re := range f.include {
+		if re.MatchString(keyPath) {
+			return false
+		}
+	}
+	return true
+}
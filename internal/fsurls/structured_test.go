@@ -0,0 +1,122 @@
+package fsurls
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCollectURLs_StructuredKeyPaths(t *testing.T) {
+	root := filepath.Join("..", "..", "testdata", "structured")
+	urls, err := CollectURLs(root, []string{"**/*"}, true)
+	if err != nil {
+		t.Fatalf("CollectURLs error: %v", err)
+	}
+
+	want := map[string]string{
+		"https://json.schemastore.org/package.json": "$schema",
+		"https://example.com/home":                  "homepage",
+		"https://example.com/b":                     "dependencies[1].homepage",
+		"https://example.com/contact":               "info.contact.url",
+		"https://example.com/first":                 "links[0]",
+		"https://example.com/second":                "links[1].href",
+		"https://example.com/crate":                 "package.homepage",
+		"https://example.com/crate-docs":            `package["documentation.url"]`,
+		"https://example.com/bin-two":               "bin[1].url",
+		"https://example.com/mirror-b":              "links.mirrors[1]",
+	}
+	for u, keyPath := range want {
+		srcs, ok := urls[u]
+		if !ok {
+			t.Fatalf("expected URL %q to be collected", u)
+		}
+		if srcs[0].KeyPath != keyPath {
+			t.Fatalf("URL %q: expected key path %q, got source %q", u, keyPath, srcs[0])
+		}
+	}
+}
+
+func TestAnnotateKeyPaths_OnlyInsideValues(t *testing.T) {
+	annotate := func(path, content string, urls ...string) map[string]string {
+		var matches []matchCandidate
+		for _, u := range urls {
+			matches = append(matches, matchCandidate{URL: u, Offset: strings.Index(content, u), Length: len(u)})
+		}
+		annotateKeyPaths(path, content, matches)
+		got := make(map[string]string)
+		for _, m := range matches {
+			got[m.URL] = m.KeyPath
+		}
+		return got
+	}
+
+	yamlDoc := `name: café ünïcödé "https://example.com/in-name"
+homepage: https://example.com/home
+# moved from https://example.com/comment
+"https://example.com/key": yes
+notes: |
+  See https://example.com/block
+  for details.
+after: &a !!str https://example.com/anchored # https://example.com/trailing
+`
+	got := annotate("config.yaml", yamlDoc,
+		"https://example.com/in-name", "https://example.com/home", "https://example.com/comment",
+		"https://example.com/key", "https://example.com/block", "https://example.com/anchored", "https://example.com/trailing")
+	want := map[string]string{
+		"https://example.com/in-name":  "name",
+		"https://example.com/home":     "homepage",
+		"https://example.com/comment":  "",
+		"https://example.com/key":      "",
+		"https://example.com/block":    "notes",
+		"https://example.com/anchored": "after",
+		"https://example.com/trailing": "",
+	}
+	for u, keyPath := range want {
+		if got[u] != keyPath {
+			t.Errorf("yaml %s: key path %q, want %q", u, got[u], keyPath)
+		}
+	}
+
+	tomlDoc := `[package]
+homepage = "https://example.com/crate" # was https://example.com/old
+# docs: https://example.com/comment
+description = """
+Mirror of https://example.com/upstream
+"""
+`
+	got = annotate("Cargo.toml", tomlDoc,
+		"https://example.com/crate", "https://example.com/old", "https://example.com/comment", "https://example.com/upstream")
+	want = map[string]string{
+		"https://example.com/crate":    "package.homepage",
+		"https://example.com/old":      "",
+		"https://example.com/comment":  "",
+		"https://example.com/upstream": "package.description",
+	}
+	for u, keyPath := range want {
+		if got[u] != keyPath {
+			t.Errorf("toml %s: key path %q, want %q", u, got[u], keyPath)
+		}
+	}
+}
+
+func TestKeyPathFilter(t *testing.T) {
+	f := newKeyPathFilter(nil, []string{"$schema", "servers", "dependencies[*].homepage"})
+	cases := map[string]bool{
+		"$schema":                  true,
+		"servers[0].url":           true,
+		"serversExtra":             false,
+		"dependencies[3].homepage": true,
+		"dependencies[3].name":     false,
+		"":                         false,
+	}
+	for keyPath, skip := range cases {
+		if got := f.Skip(keyPath); got != skip {
+			t.Fatalf("Skip(%q) = %v, want %v", keyPath, got, skip)
+		}
+	}
+
+	only := newKeyPathFilter([]string{"info.**"}, nil)
+	if only.Skip("info.contact.url") || !only.Skip("paths.x") {
+		t.Fatalf("includeKeys should keep only matching key paths")
+	}
+}
@@ -0,0 +1,72 @@
+package jsonpointer
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Resolve walks doc along an RFC 6901 pointer (e.g. "/components/schemas/Pet")
+// and returns the node it points at. doc may be a document node or any node
+// within a parsed YAML/JSON tree.
+func Resolve(doc *yaml.Node, pointer string) (*yaml.Node, error) {
+	n := doc
+	if n != nil && n.Kind == yaml.DocumentNode && len(n.Content) > 0 {
+		n = n.Content[0]
+	}
+	if pointer == "" {
+		return n, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q", pointer)
+	}
+	for _, tok := range strings.Split(pointer[1:], "/") {
+		tok = strings.ReplaceAll(strings.ReplaceAll(tok, "~1", "/"), "~0", "~")
+		for n != nil && n.Kind == yaml.AliasNode {
+			n = n.Alias
+		}
+		if n == nil {
+			return nil, fmt.Errorf("pointer %q not found", pointer)
+		}
+		switch n.Kind {
+		case yaml.MappingNode:
+			var next *yaml.Node
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				if n.Content[i].Value == tok {
+					next = n.Content[i+1]
+					break
+				}
+			}
+			if next == nil {
+				return nil, fmt.Errorf("pointer %q not found: no key %q", pointer, tok)
+			}
+			n = next
+		case yaml.SequenceNode:
+			idx, err := strconv.Atoi(tok)
+			if err != nil || idx < 0 || idx >= len(n.Content) {
+				return nil, fmt.Errorf("pointer %q not found: no index %q", pointer, tok)
+			}
+			n = n.Content[idx]
+		default:
+			return nil, fmt.Errorf("pointer %q not found: %q is not a container", pointer, tok)
+		}
+	}
+	return n, nil
+}
+
+// ResolveFile parses the YAML or JSON file at path and resolves pointer in it.
+func ResolveFile(path, pointer string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return fmt.Errorf("parse %s: %w", path, err)
+	}
+	_, err = Resolve(&doc, pointer)
+	return err
+}
@@ -0,0 +1,124 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"slinky/internal/web"
+)
+
+// maxLatencyRows bounds the latency table to the slowest hosts.
+const maxLatencyRows = 20
+
+// HostLatency summarizes response times for one host.
+type HostLatency struct {
+	Host string
+	// Links counts the timed links that Avg, Max and Slow cover.
+	Links int
+	Avg   time.Duration
+	Max   time.Duration
+	// Slow counts links over the slow threshold.
+	Slow int
+	// Cached counts links answered from the cache, which have no timing.
+	Cached int
+}
+
+// HostLatencies groups the timed results of working links by host, slowest
+// average first. Failures are left out since they often end early. Cached
+// passes are counted apart, so they don't read as instant responses.
+func HostLatencies(results []web.Result, threshold time.Duration) []HostLatency {
+	byHost := make(map[string]*HostLatency)
+	total := make(map[string]time.Duration)
+	for _, r := range results {
+		if !r.OK || (r.Timing == nil && !r.CacheHit) {
+			continue
+		}
+		host := hostOf(r)
+		if host == "" {
+			continue
+		}
+		h, ok := byHost[host]
+		if !ok {
+			h = &HostLatency{Host: host}
+			byHost[host] = h
+		}
+		if r.Timing == nil {
+			h.Cached++
+			continue
+		}
+		d := r.Timing.Total
+		h.Links++
+		total[host] += d
+		if d > h.Max {
+			h.Max = d
+		}
+		if threshold > 0 && d > threshold {
+			h.Slow++
+		}
+	}
+	out := make([]HostLatency, 0, len(byHost))
+	for host, h := range byHost {
+		if h.Links > 0 {
+			h.Avg = total[host] / time.Duration(h.Links)
+		}
+		out = append(out, *h)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Avg != out[j].Avg {
+			return out[i].Avg > out[j].Avg
+		}
+		return out[i].Host < out[j].Host
+	})
+	return out
+}
+
+func hostOf(r web.Result) string {
+	raw := r.URL
+	if r.RewrittenURL != "" {
+		raw = r.RewrittenURL
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Host)
+}
+
+// writeLatencyTable renders the slowest hosts as a Markdown table.
+func writeLatencyTable(buf *bytes.Buffer, rows []HostLatency, threshold time.Duration) {
+	buf.WriteString("### Latency by host\n\n")
+	if threshold > 0 {
+		buf.WriteString(fmt.Sprintf("Slow threshold: %s\n\n", threshold))
+	}
+	buf.WriteString("| Host | Links | Avg | Max | Slow | Cached |\n")
+	buf.WriteString("|---|---:|---:|---:|---:|---:|\n")
+	for i, h := range rows {
+		if i == maxLatencyRows {
+			break
+		}
+		avg, slowest := "-", "-"
+		if h.Links > 0 {
+			avg, slowest = h.Avg.Round(time.Millisecond).String(), h.Max.Round(time.Millisecond).String()
+		}
+		buf.WriteString(fmt.Sprintf("| %s | %d | %s | %s | %d | %d |\n", escapeMD(h.Host), h.Links, avg, slowest, h.Slow, h.Cached))
+	}
+	if cachedLinks(rows) > 0 {
+		buf.WriteString("\nCached links were not requested in this run and are not timed.\n")
+	}
+	if len(rows) > maxLatencyRows {
+		buf.WriteString(fmt.Sprintf("\n%d more hosts not shown.\n", len(rows)-maxLatencyRows))
+	}
+	buf.WriteString("\n")
+}
+
+func cachedLinks(rows []HostLatency) int {
+	n := 0
+	for _, h := range rows {
+		n += h.Cached
+	}
+	return n
+}
@@ -0,0 +1,68 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"slinky/internal/web"
+)
+
+func TestHostLatencies(t *testing.T) {
+	timed := func(raw string, ok bool, total time.Duration) web.Result {
+		return web.Result{URL: raw, OK: ok, Timing: &web.Timing{Total: total}}
+	}
+	results := []web.Result{
+		timed("https://a.example/1", true, 100*time.Millisecond),
+		timed("https://a.example/2", true, 500*time.Millisecond),
+		timed("https://A.example/3", true, 300*time.Millisecond),
+		// Failures are left out even when they took longest
+		timed("https://a.example/4", false, 5*time.Second),
+		timed("https://c.example/1", false, time.Second),
+		timed("https://b.example/1", true, 350*time.Millisecond),
+		{URL: "https://b.example/untimed", OK: true},
+		{URL: "https://old.example/x", RewrittenURL: "https://b.example/x", OK: true, Timing: &web.Timing{Total: 450 * time.Millisecond}},
+		// Cache hits are counted, not averaged in as instant responses
+		{URL: "https://a.example/cached", OK: true, CacheHit: true},
+		{URL: "https://d.example/cached", OK: true, CacheHit: true},
+	}
+	got := HostLatencies(results, 300*time.Millisecond)
+	want := []HostLatency{
+		{Host: "b.example", Links: 2, Avg: 400 * time.Millisecond, Max: 450 * time.Millisecond, Slow: 2},
+		{Host: "a.example", Links: 3, Avg: 300 * time.Millisecond, Max: 500 * time.Millisecond, Slow: 1, Cached: 1},
+		{Host: "d.example", Cached: 1},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("row %d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	for _, h := range HostLatencies(results, 0) {
+		if h.Slow != 0 {
+			t.Fatalf("%s: %d slow links without a threshold", h.Host, h.Slow)
+		}
+	}
+}
+
+func TestWriteLatencyTable_Cached(t *testing.T) {
+	var buf bytes.Buffer
+	writeLatencyTable(&buf, []HostLatency{
+		{Host: "a.example", Links: 1, Avg: 200 * time.Millisecond, Max: 200 * time.Millisecond, Cached: 2},
+		{Host: "d.example", Cached: 1},
+	}, 0)
+	out := buf.String()
+	for _, want := range []string{
+		"| a.example | 1 | 200ms | 200ms | 0 | 2 |",
+		"| d.example | 0 | - | - | 0 | 1 |",
+		"Cached links were not requested in this run",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("missing %q in:\n%s", want, out)
+		}
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"strings"
 	"time"
 
+	"slinky/internal/fsurls"
 	"slinky/internal/web"
 )
 
@@ -21,16 +22,22 @@ type Summary struct {
 	Processed       int
 	OK              int
 	Fail            int
+	Skipped         int
+	Warn            int
 	AvgRPS          float64
 	PeakRPS         float64
 	LowRPS          float64
 	FilesScanned    int
 	JSONPath        string
 	RepoBlobBaseURL string // e.g. https://github.com/owner/repo/blob/<sha>
+	// Latency, when set, is rendered as a per-host table of response times.
+	Latency       []HostLatency
+	SlowThreshold time.Duration
 }
 
 // WriteMarkdown writes a GitHub-flavored Markdown report to path. If path is empty,
-// it derives a safe filename from s.RootPath.
+// it derives a safe filename from s.RootPath. results should hold only failed
+// links and links with warnings; they are listed in separate sections.
 func WriteMarkdown(path string, results []web.Result, s Summary) (string, error) {
 	if strings.TrimSpace(path) == "" {
 		base := filepath.Base(s.RootPath)
@@ -66,6 +73,12 @@ func WriteMarkdown(path string, results []web.Result, s Summary) (string, error)
 	// Summary list: Pass, Fail, Total
 	buf.WriteString(fmt.Sprintf("- **Pass**: %d\n", s.OK))
 	buf.WriteString(fmt.Sprintf("- **Fail**: %d\n", s.Fail))
+	if s.Warn > 0 {
+		buf.WriteString(fmt.Sprintf("- **Warnings**: %d\n", s.Warn))
+	}
+	if s.Skipped > 0 {
+		buf.WriteString(fmt.Sprintf("- **Skipped**: %d\n", s.Skipped))
+	}
 	buf.WriteString(fmt.Sprintf("- **Total**: %d\n", s.Processed))
 	if s.FilesScanned > 0 {
 		buf.WriteString(fmt.Sprintf("- **Files Scanned**: %d\n", s.FilesScanned))
@@ -83,6 +96,10 @@ func WriteMarkdown(path string, results []web.Result, s Summary) (string, error)
 
 	buf.WriteString("\n")
 
+	if len(s.Latency) > 0 {
+		writeLatencyTable(&buf, s.Latency, s.SlowThreshold)
+	}
+
 	// If no failures, show message and finish
 	if len(results) == 0 {
 		buf.WriteString("No issues found. ✅\n")
@@ -97,27 +114,62 @@ func WriteMarkdown(path string, results []web.Result, s Summary) (string, error)
 		return path, nil
 	}
 
-	// Failures by URL
-	buf.WriteString("### Failures by URL\n\n")
+	var failures, warnings []web.Result
+	for _, r := range results {
+		if r.Severity == web.SeverityWarn {
+			warnings = append(warnings, r)
+		} else {
+			failures = append(failures, r)
+		}
+	}
+	if len(failures) > 0 {
+		buf.WriteString("### Failures by URL\n\n")
+		writeURLSection(&buf, failures, s)
+	}
+	if len(warnings) > 0 {
+		if len(failures) > 0 {
+			buf.WriteString("\n")
+		}
+		buf.WriteString("### Warnings by URL\n\n")
+		writeURLSection(&buf, warnings, s)
+	}
 
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// writeURLSection lists results grouped by URL with the files they occur in.
+func writeURLSection(buf *bytes.Buffer, results []web.Result, s Summary) {
 	// Gather issues per URL with list of files
-	type fileRef struct{ Path string }
 	type urlIssue struct {
-		Status int
-		Method string
-		ErrMsg string
-		Files  []fileRef
+		Rewritten string
+		Status    int
+		Method    string
+		ErrMsg    string
+		Files     []fsurls.Source
 	}
 	byURL := make(map[string]*urlIssue)
 	for _, r := range results {
 		ui, ok := byURL[r.URL]
 		if !ok {
-			ui = &urlIssue{Status: r.Status, Method: r.Method, ErrMsg: r.ErrMsg}
+			msg := r.ErrMsg
+			if msg == "" {
+				msg = strings.Join(r.Warnings, "; ")
+			}
+			if r.ErrorKind != "" {
+				msg = strings.TrimSpace(msg + " [" + string(r.ErrorKind) + "]")
+			}
+			ui = &urlIssue{Rewritten: r.RewrittenURL, Status: r.Status, Method: r.Method, ErrMsg: msg}
 			byURL[r.URL] = ui
 		}
-		for _, src := range r.Sources {
-			ui.Files = append(ui.Files, fileRef{Path: src})
-		}
+		ui.Files = append(ui.Files, r.Sources...)
 	}
 
 	// Sort URLs
@@ -129,35 +181,36 @@ func WriteMarkdown(path string, results []web.Result, s Summary) (string, error)
 
 	for _, u := range urls {
 		ui := byURL[u]
+		target := fmt.Sprintf("`%s`", escapeMD(u))
+		if ui.Rewritten != "" {
+			target = fmt.Sprintf("`%s` → `%s`", escapeMD(u), escapeMD(ui.Rewritten))
+		}
 		if ui.Status > 0 {
-			buf.WriteString(fmt.Sprintf("- %d %s `%s` — %s\n", ui.Status, escapeMD(ui.Method), escapeMD(u), escapeMD(ui.ErrMsg)))
+			buf.WriteString(fmt.Sprintf("- %d %s %s — %s\n", ui.Status, escapeMD(ui.Method), target, escapeMD(ui.ErrMsg)))
 		} else {
-			buf.WriteString(fmt.Sprintf("- %s `%s` — %s\n", escapeMD(ui.Method), escapeMD(u), escapeMD(ui.ErrMsg)))
+			buf.WriteString(fmt.Sprintf("- %s %s — %s\n", escapeMD(ui.Method), target, escapeMD(ui.ErrMsg)))
 		}
 		seen := make(map[string]struct{})
-		var files []string
-		for _, fr := range ui.Files {
-			if _, ok := seen[fr.Path]; ok {
+		var files []fsurls.Source
+		for _, src := range ui.Files {
+			if _, ok := seen[src.String()]; ok {
 				continue
 			}
-			seen[fr.Path] = struct{}{}
-			files = append(files, fr.Path)
+			seen[src.String()] = struct{}{}
+			files = append(files, src)
 		}
-		sort.Strings(files)
-		for _, fn := range files {
-			display := fn
-			linkPath := fn
-			if parts := strings.Split(fn, "|"); len(parts) >= 2 {
-				p := parts[0]
-				line := strings.TrimSpace(parts[1])
-				display = p
-				if line != "" {
-					linkPath = fmt.Sprintf("%s#L%s", escapeLinkPath(p), line)
-				} else {
-					linkPath = escapeLinkPath(p)
+		fsurls.SortSources(files)
+		for _, src := range files {
+			display := src.File
+			if src.KeyPath != "" {
+				display = fmt.Sprintf("%s (%s)", src.File, src.KeyPath)
+			}
+			linkPath := escapeLinkPath(src.File)
+			if src.StartLine > 0 {
+				linkPath = fmt.Sprintf("%s#L%d", linkPath, src.StartLine)
+				if src.EndLine > src.StartLine {
+					linkPath = fmt.Sprintf("%s-L%d", linkPath, src.EndLine)
 				}
-			} else {
-				linkPath = escapeLinkPath(linkPath)
 			}
 			if strings.TrimSpace(s.RepoBlobBaseURL) != "" {
 				buf.WriteString(fmt.Sprintf("  - [%s](%s/%s)\n", escapeMD(display), strings.TrimRight(s.RepoBlobBaseURL, "/"), linkPath))
@@ -170,15 +223,6 @@ func WriteMarkdown(path string, results []web.Result, s Summary) (string, error)
 		}
 	}
 
-	f, err := os.Create(path)
-	if err != nil {
-		return "", err
-	}
-	defer f.Close()
-	if _, err := f.Write(buf.Bytes()); err != nil {
-		return "", err
-	}
-	return path, nil
 }
 
 func escapeMD(s string) string {
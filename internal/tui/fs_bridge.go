@@ -5,10 +5,10 @@ import (
 )
 
 // fsCollect is a tiny bridge to avoid importing fsurls directly in tui.go
-func fsCollect(root string, globs []string) (map[string][]string, error) {
+func fsCollect(root string, globs []string) (map[string][]fsurls.Source, error) {
 	return fsurls.CollectURLs(root, globs, true)
 }
 
-func fsCollectProgress(root string, globs []string, onFile func(string)) (map[string][]string, error) {
+func fsCollectProgress(root string, globs []string, onFile func(string)) (map[string][]fsurls.Source, error) {
 	return fsurls.CollectURLsProgress(root, globs, true, onFile)
 }
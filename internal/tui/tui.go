@@ -51,9 +51,12 @@ type model struct {
 
 	lines []string
 
-	total int
-	ok    int
-	fail  int
+	total   int
+	ok      int
+	fail    int
+	skipped int
+	warn    int
+	cached  int
 
 	pending       int
 	processed     int
@@ -332,6 +335,9 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.total = 0
 				m.ok = 0
 				m.fail = 0
+				m.skipped = 0
+				m.warn = 0
+				m.cached = 0
 				m.processed = 0
 				m.lastProcessed = 0
 				m.filesScanned = 0
@@ -369,21 +375,40 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case linkResultMsg:
 		// Show every event in the log
 		prefix := statusEmoji(msg.res.OK, msg.res.Err)
-		if msg.res.CacheHit {
+		if msg.res.Skipped {
+			prefix = "⏭"
+		} else if msg.res.Severity == web.SeverityWarn {
+			prefix = "⚠️"
+		} else if msg.res.CacheHit && msg.res.Severity == web.SeverityOK {
 			prefix = "🗃"
 		}
 		line := fmt.Sprintf("%s %3d %s", prefix, msg.res.Status, msg.res.URL)
+		if msg.res.RewrittenURL != "" {
+			line += " → " + msg.res.RewrittenURL
+		}
+		if msg.res.SuggestedURL != "" {
+			line += " (use " + msg.res.SuggestedURL + ")"
+		}
+		if !msg.res.OK && msg.res.ErrorKind != "" {
+			line += " [" + string(msg.res.ErrorKind) + "]"
+		}
 		m.lines = append(m.lines, line)
-		// Only count non-cache-hit in totals and JSON export
-		if !msg.res.CacheHit {
-			m.total++
-			if msg.res.OK && msg.res.Err == nil {
-				m.ok++
-			} else {
-				m.fail++
-			}
-			m.allResults = append(m.allResults, msg.res)
+		// Cached results count like fresh ones so stale failures still show
+		m.total++
+		if msg.res.CacheHit {
+			m.cached++
+		}
+		switch {
+		case msg.res.Skipped:
+			m.skipped++
+		case msg.res.Severity == web.SeverityError:
+			m.fail++
+		case msg.res.Severity == web.SeverityWarn:
+			m.warn++
+		default:
+			m.ok++
 		}
+		m.allResults = append(m.allResults, msg.res)
 		m.refreshViewport()
 		return m, m.waitForEvent()
 	case statsMsg:
@@ -441,6 +466,9 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.total = 0
 		m.ok = 0
 		m.fail = 0
+		m.skipped = 0
+		m.warn = 0
+		m.cached = 0
 		m.processed = 0
 		m.lastProcessed = 0
 		m.filesScanned = 0
@@ -502,10 +530,11 @@ func (m *model) writeJSON() {
 		return
 	}
 	defer f.Close()
-	// Only write failing results
+	// Only write failing results, those with warnings and passes decided by a
+	// status policy
 	var fails []web.Result
 	for _, r := range m.allResults {
-		if !(r.OK && r.Err == nil) {
+		if r.Skipped || r.Severity != web.SeverityOK || r.StatusPolicyDecided() {
 			fails = append(fails, r)
 		}
 	}
@@ -529,16 +558,22 @@ func (m *model) writeMarkdown() {
 		Processed:       m.processed,
 		OK:              m.ok,
 		Fail:            m.fail,
+		Skipped:         m.skipped,
+		Warn:            m.warn,
 		AvgRPS:          avg,
 		PeakRPS:         m.peakRPS,
 		LowRPS:          m.lowRPS,
 		JSONPath:        m.jsonPath,
 		RepoBlobBaseURL: os.Getenv("SLINKY_REPO_BLOB_BASE_URL"),
+		SlowThreshold:   m.cfg.SlowThreshold,
+	}
+	if m.cfg.SlowThreshold > 0 {
+		s.Latency = report.HostLatencies(m.allResults, m.cfg.SlowThreshold)
 	}
-	// Only include failing results in the markdown report
+	// Only include failing results and warnings in the markdown report
 	var failsMD []web.Result
 	for _, r := range m.allResults {
-		if !(r.OK && r.Err == nil) {
+		if r.Severity != web.SeverityOK {
 			failsMD = append(failsMD, r)
 		}
 	}
@@ -565,7 +600,7 @@ func (m *model) View() string {
 		}
 		summary := []string{
 			fmt.Sprintf("Duration: %s", dur.Truncate(time.Millisecond)),
-			fmt.Sprintf("Processed: %d  OK:%d  Fail:%d", m.processed, m.ok, m.fail),
+			fmt.Sprintf("Processed: %d  OK:%d  Warn:%d  Fail:%d  Skipped:%d", m.processed, m.ok, m.warn, m.fail, m.skipped),
 			fmt.Sprintf("Rates: avg %.1f/s  peak %.1f/s  low %.1f/s", avg, m.peakRPS, m.lowRPS),
 			fmt.Sprintf("Files scanned: %d", m.filesScanned),
 		}
@@ -586,7 +621,7 @@ func (m *model) View() string {
 		percent = float64(m.processed) / float64(totalWork)
 	}
 	progressLine := m.prog.ViewAs(percent)
-	stats := fmt.Sprintf("%s  total:%d  ok:%d  fail:%d  pending:%d processed:%d  rps:%.1f/s  files:%d", m.spin.View(), m.total, m.ok, m.fail, m.pending, m.processed, m.rps, m.filesScanned)
+	stats := fmt.Sprintf("%s  total:%d  ok:%d  warn:%d  fail:%d  skipped:%d  cached:%d  pending:%d processed:%d  rps:%.1f/s  files:%d", m.spin.View(), m.total, m.ok, m.warn, m.fail, m.skipped, m.cached, m.pending, m.processed, m.rps, m.filesScanned)
 	body := m.vp.View()
 	footerText := "Controls: [q] quit  [f] toggle fails"
 	footer := lipgloss.NewStyle().Faint(true).Render(footerText)
@@ -0,0 +1,90 @@
+package web
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// HostAuth holds credentials sent to hosts whose name matches the Match glob.
+// Credentials go only to matching hosts and, unless AllowHTTP is set, only
+// over HTTPS.
+type HostAuth struct {
+	Match     string
+	Headers   map[string]string
+	Bearer    string
+	Username  string
+	Password  string
+	Cookies   map[string]string
+	AllowHTTP bool
+}
+
+// String redacts the credentials so an entry is safe to print.
+func (a HostAuth) String() string { return fmt.Sprintf("auth for %s", a.Match) }
+
+// GoString redacts the credentials for %#v as well.
+func (a HostAuth) GoString() string { return a.String() }
+
+// authFor returns the first entry matching hostname.
+func authFor(auth []HostAuth, hostname string) (HostAuth, bool) {
+	for _, a := range auth {
+		if matchHost(a.Match, hostname) {
+			return a, true
+		}
+	}
+	return HostAuth{}, false
+}
+
+// authTransport adds configured credentials to each request. It works per
+// request, so redirect hops to other hosts never receive them.
+type authTransport struct {
+	base http.RoundTripper
+	auth []HostAuth
+}
+
+func newAuthTransport(base http.RoundTripper, auth []HostAuth) http.RoundTripper {
+	if len(auth) == 0 {
+		return base
+	}
+	return &authTransport{base: base, auth: auth}
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	a, ok := authFor(t.auth, req.URL.Hostname())
+	if !ok || (req.URL.Scheme != "https" && !a.AllowHTTP) {
+		return t.base.RoundTrip(req)
+	}
+	req = req.Clone(req.Context())
+	// Headers the caller set, such as the GitHub API token, take precedence
+	for k, v := range a.Headers {
+		if req.Header.Get(k) == "" {
+			req.Header.Set(k, v)
+		}
+	}
+	if req.Header.Get("Authorization") == "" {
+		switch {
+		case a.Bearer != "":
+			req.Header.Set("Authorization", "Bearer "+a.Bearer)
+		case a.Username != "" || a.Password != "":
+			req.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(a.Username+":"+a.Password)))
+		}
+	}
+	if len(a.Cookies) > 0 {
+		var parts []string
+		if c := req.Header.Get("Cookie"); c != "" {
+			parts = append(parts, c)
+		}
+		names := make([]string, 0, len(a.Cookies))
+		for name := range a.Cookies {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			parts = append(parts, (&http.Cookie{Name: name, Value: a.Cookies[name]}).String())
+		}
+		req.Header.Set("Cookie", strings.Join(parts, "; "))
+	}
+	return t.base.RoundTrip(req)
+}
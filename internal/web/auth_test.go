@@ -0,0 +1,65 @@
+package web
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type recordingTransport struct {
+	seen map[string]http.Header
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.seen[req.URL.String()] = req.Header.Clone()
+	resp := &http.Response{StatusCode: 200, Header: http.Header{}, Body: io.NopCloser(strings.NewReader("")), Request: req}
+	if req.URL.Path == "/moved" {
+		resp.StatusCode = http.StatusFound
+		resp.Header.Set("Location", "https://elsewhere.example.net/landing")
+	}
+	return resp, nil
+}
+
+func TestAuthTransport_OnlyMatchingHosts(t *testing.T) {
+	rec := &recordingTransport{seen: make(map[string]http.Header)}
+	auth := []HostAuth{
+		{Match: "docs.example.com", Bearer: "s3cret", Headers: map[string]string{"X-Team": "docs"}, Cookies: map[string]string{"session": "abc"}},
+		{Match: "*.example.com", Username: "user", Password: "pw"},
+	}
+	client := &http.Client{Transport: newAuthTransport(rec, auth), CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+
+	if _, err := fetch(context.Background(), client, http.MethodGet, "https://docs.example.com/moved", 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	for _, raw := range []string{"https://wiki.example.com/", "http://docs.example.com/"} {
+		if _, err := fetch(context.Background(), client, http.MethodGet, raw, 0, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	h := rec.seen["https://docs.example.com/moved"]
+	if h.Get("Authorization") != "Bearer s3cret" || h.Get("X-Team") != "docs" || h.Get("Cookie") != "session=abc" {
+		t.Fatalf("docs headers = %v", h)
+	}
+	if h := rec.seen["https://elsewhere.example.net/landing"]; h == nil || h.Get("Authorization") != "" || h.Get("Cookie") != "" || h.Get("X-Team") != "" {
+		t.Fatalf("credentials followed the redirect: %v", h)
+	}
+	if h := rec.seen["https://wiki.example.com/"]; h.Get("Authorization") != "Basic dXNlcjpwdw==" {
+		t.Fatalf("wiki Authorization = %q", h.Get("Authorization"))
+	}
+	if h := rec.seen["http://docs.example.com/"]; h.Get("Authorization") != "" {
+		t.Fatal("credentials sent over plain HTTP")
+	}
+}
+
+func TestHostAuth_Redacted(t *testing.T) {
+	a := HostAuth{Match: "docs.example.com", Bearer: "s3cret", Password: "pw"}
+	for _, s := range []string{fmt.Sprint(a), fmt.Sprintf("%+v", a), fmt.Sprintf("%#v", a), fmt.Sprint([]HostAuth{a})} {
+		if strings.Contains(s, "s3cret") || strings.Contains(s, "pw") {
+			t.Fatalf("credentials printed: %s", s)
+		}
+	}
+}
@@ -0,0 +1,373 @@
+package web
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultCachePath is where the result cache lives unless configured.
+const DefaultCachePath = ".slinky/cache/results.json"
+
+const (
+	defaultCacheTTL     = 24 * time.Hour
+	defaultCacheFailTTL = time.Hour
+	cacheVersion        = 1
+)
+
+// CacheEntry is a stored check result with the validators needed to
+// revalidate it.
+type CacheEntry struct {
+	URL          string        `json:"url"`
+	OK           bool          `json:"ok"`
+	Status       int           `json:"status"`
+	ErrMsg       string        `json:"error,omitempty"`
+	ErrorKind    ErrorKind     `json:"errorKind,omitempty"`
+	Method       string        `json:"method,omitempty"`
+	ContentType  string        `json:"contentType,omitempty"`
+	StatusRule   string        `json:"statusRule,omitempty"`
+	Redirects    []RedirectHop `json:"redirects,omitempty"`
+	Warnings     []string      `json:"warnings,omitempty"`
+	SuggestedURL string        `json:"suggestedUrl,omitempty"`
+	CheckedAt    time.Time     `json:"checkedAt"`
+	ETag         string        `json:"etag,omitempty"`
+	LastModified string        `json:"lastModified,omitempty"`
+	// Config fingerprints the settings the verdict was reached under; entries
+	// from other settings are rechecked.
+	Config string `json:"config,omitempty"`
+}
+
+// Cache is a persistent map from canonical URL to the last check result. It
+// is safe for concurrent use.
+type Cache struct {
+	path    string
+	mu      sync.Mutex
+	entries map[string]CacheEntry
+}
+
+type cacheFile struct {
+	Version int                   `json:"version"`
+	Entries map[string]CacheEntry `json:"entries"`
+}
+
+// OpenCache loads the cache at path; a missing file yields an empty cache.
+func OpenCache(path string) (*Cache, error) {
+	c := &Cache{path: path, entries: make(map[string]CacheEntry)}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var f cacheFile
+	if err := json.Unmarshal(b, &f); err != nil {
+		return nil, err
+	}
+	// Entries from other versions are dropped rather than misread
+	if f.Version == cacheVersion && f.Entries != nil {
+		c.entries = f.Entries
+	}
+	return c, nil
+}
+
+// Path returns the file the cache is stored in.
+func (c *Cache) Path() string { return c.path }
+
+// Save writes the cache atomically, creating its directory if needed.
+func (c *Cache) Save() error {
+	c.mu.Lock()
+	b, err := json.MarshalIndent(cacheFile{Version: cacheVersion, Entries: c.entries}, "", "  ")
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), ".cache-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path)
+}
+
+// Get returns the entry for raw, looked up by canonical URL.
+func (c *Cache) Get(raw string) (CacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[CanonicalURL(raw)]
+	return e, ok
+}
+
+// Put stores e under the canonical form of e.URL.
+func (c *Cache) Put(e CacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[CanonicalURL(e.URL)] = e
+}
+
+// Entries returns all entries sorted by URL.
+func (c *Cache) Entries() []CacheEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make([]CacheEntry, 0, len(c.entries))
+	for _, e := range c.entries {
+		out = append(out, e)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].URL < out[j].URL })
+	return out
+}
+
+// Prune removes the entries for which drop returns true and reports how many
+// were removed.
+func (c *Cache) Prune(drop func(CacheEntry) bool) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := 0
+	for k, e := range c.entries {
+		if drop(e) {
+			delete(c.entries, k)
+			n++
+		}
+	}
+	return n
+}
+
+// Fresh reports whether e can be reused without a request: passes live for
+// ttl and failures for failTTL (defaults 24h and 1h).
+func (e CacheEntry) Fresh(now time.Time, ttl, failTTL time.Duration) bool {
+	if ttl <= 0 {
+		ttl = defaultCacheTTL
+	}
+	if failTTL <= 0 {
+		failTTL = defaultCacheFailTTL
+	}
+	age := now.Sub(e.CheckedAt)
+	if e.OK {
+		return age < ttl
+	}
+	return age < failTTL
+}
+
+// CanonicalURL normalizes raw for use as a cache key: lowercase scheme and
+// host, no default port, and "/" for an empty path.
+func CanonicalURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return raw
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Hostname())
+	port := u.Port()
+	if (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		port = ""
+	}
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if port != "" {
+		host += ":" + port
+	}
+	u.Host = host
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	return u.String()
+}
+
+// cacheable reports whether results from method are worth persisting; local
+// checks are cheap and their targets change with the working tree.
+func cacheable(method string) bool {
+	switch method {
+	case "HEAD", "GET", "GITHUB", "FTP":
+		return true
+	}
+	return false
+}
+
+// newCacheEntry stores what res says about the link. A slow response is a
+// finding about this run, not the link, so it is left out.
+func newCacheEntry(url string, res Result, now time.Time) CacheEntry {
+	kind := res.ErrorKind
+	if kind == ErrorKindSlow {
+		kind = ""
+	}
+	warnings := slices.DeleteFunc(slices.Clone(res.Warnings), func(w string) bool {
+		return strings.HasPrefix(w, slowWarningPrefix)
+	})
+	return CacheEntry{
+		URL:          url,
+		OK:           res.OK,
+		Status:       res.Status,
+		ErrMsg:       errString(res.Err),
+		ErrorKind:    kind,
+		Method:       res.Method,
+		ContentType:  res.ContentType,
+		StatusRule:   res.StatusRule,
+		Redirects:    res.Redirects,
+		Warnings:     warnings,
+		SuggestedURL: res.SuggestedURL,
+		CheckedAt:    now,
+		ETag:         res.etag,
+		LastModified: res.lastModified,
+	}
+}
+
+// result rebuilds a Result from the entry.
+func (e CacheEntry) result() Result {
+	res := Result{
+		OK:           e.OK,
+		Status:       e.Status,
+		ErrorKind:    e.ErrorKind,
+		Method:       e.Method,
+		ContentType:  e.ContentType,
+		StatusRule:   e.StatusRule,
+		Redirects:    e.Redirects,
+		Warnings:     e.Warnings,
+		SuggestedURL: e.SuggestedURL,
+		CacheHit:     true,
+		etag:         e.ETag,
+		lastModified: e.LastModified,
+	}
+	if e.ErrMsg != "" {
+		res.Err = simpleError(e.ErrMsg)
+	}
+	return res
+}
+
+type validatorsKey struct{}
+
+// cacheValidators are sent as If-None-Match / If-Modified-Since on the first
+// request for url.
+type cacheValidators struct {
+	url          string
+	etag         string
+	lastModified string
+}
+
+func withValidators(ctx context.Context, v cacheValidators) context.Context {
+	return context.WithValue(ctx, validatorsKey{}, v)
+}
+
+func validatorsFor(ctx context.Context, raw string) (cacheValidators, bool) {
+	v, ok := ctx.Value(validatorsKey{}).(cacheValidators)
+	if !ok || v.url != raw || (v.etag == "" && v.lastModified == "") {
+		return cacheValidators{}, false
+	}
+	return v, true
+}
+
+// cacheFingerprint hashes the settings that turn a response into a verdict,
+// so changing a status policy or soft-404 setting invalidates cached results.
+// Credentials are represented by where they apply, not by their values.
+func cacheFingerprint(cfg Config) string {
+	type authShape struct {
+		Match                    string
+		Headers, Cookies         []string
+		Bearer, Basic, AllowHTTP bool
+	}
+	type tlsShape struct {
+		Match                string
+		ClientCert, Insecure bool
+	}
+	shape := struct {
+		StatusPolicies []StatusPolicy
+		Soft404        Soft404Config
+		HTTPSUpgrade   bool
+		Hosts          []HostConfig
+		MaxRedirects   int
+		MaxBodyBytes   int64
+		GitHub         []string
+		Auth           []authShape
+		TLS            []tlsShape
+		CustomRoots    bool
+	}{
+		StatusPolicies: cfg.StatusPolicies,
+		Soft404:        cfg.Soft404,
+		HTTPSUpgrade:   cfg.HTTPSUpgrade,
+		Hosts:          cfg.Hosts,
+		MaxRedirects:   cfg.MaxRedirects,
+		MaxBodyBytes:   cfg.MaxBodyBytes,
+		CustomRoots:    cfg.TLS.RootCAs != nil,
+	}
+	if cfg.GitHub.Enabled {
+		shape.GitHub = append([]string{cfg.GitHub.APIBase, strconv.FormatBool(cfg.GitHub.Token != "")}, cfg.GitHub.Hosts...)
+	}
+	for _, a := range cfg.Auth {
+		shape.Auth = append(shape.Auth, authShape{
+			Match:     a.Match,
+			Headers:   sortedKeys(a.Headers),
+			Cookies:   sortedKeys(a.Cookies),
+			Bearer:    a.Bearer != "",
+			Basic:     a.Username != "" || a.Password != "",
+			AllowHTTP: a.AllowHTTP,
+		})
+	}
+	for _, h := range cfg.TLS.Hosts {
+		shape.TLS = append(shape.TLS, tlsShape{Match: h.Match, ClientCert: h.Certificate != nil, Insecure: h.InsecureSkipVerify})
+	}
+	b, _ := json.Marshal(shape)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:8])
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// checkCached consults the cache around check: fresh entries are reused,
+// stale passes with validators are revalidated, and new results are stored.
+// Entries reached under different settings are ignored.
+func checkCached(ctx context.Context, cache *Cache, cfg Config, target string, check func(context.Context, string) Result) Result {
+	if cache == nil {
+		return check(ctx, target)
+	}
+	now := time.Now()
+	fingerprint := cacheFingerprint(cfg)
+	entry, found := cache.Get(target)
+	found = found && entry.Config == fingerprint
+	if found && entry.Fresh(now, cfg.CacheTTL, cfg.CacheFailTTL) {
+		return entry.result()
+	}
+	if found && entry.OK && len(entry.Redirects) == 0 {
+		ctx = withValidators(ctx, cacheValidators{url: target, etag: entry.ETag, lastModified: entry.LastModified})
+	}
+	res := check(ctx, target)
+	if res.notModified {
+		entry.CheckedAt = now
+		cache.Put(entry)
+		return entry.result()
+	}
+	if cacheable(res.Method) && ctx.Err() == nil {
+		entry = newCacheEntry(target, res, now)
+		entry.Config = fingerprint
+		cache.Put(entry)
+	}
+	return res
+}
@@ -0,0 +1,165 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCanonicalURL(t *testing.T) {
+	cases := map[string]string{
+		"HTTPS://Example.COM":           "https://example.com/",
+		"https://example.com:443/a?b=1": "https://example.com/a?b=1",
+		"http://example.com:80/a#frag":  "http://example.com/a#frag",
+		"http://example.com:8080/a":     "http://example.com:8080/a",
+		"mailto:someone@example.com":    "mailto:someone@example.com",
+	}
+	for in, want := range cases {
+		if got := CanonicalURL(in); got != want {
+			t.Errorf("CanonicalURL(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestCache_SaveAndReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "results.json")
+	c, err := OpenCache(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().Truncate(time.Second)
+	c.Put(CacheEntry{URL: "https://Example.com", OK: true, Status: 200, CheckedAt: now, ETag: `"v1"`})
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+	c, err = OpenCache(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, ok := c.Get("https://example.com/")
+	if !ok || e.Status != 200 || e.ETag != `"v1"` || !e.CheckedAt.Equal(now) {
+		t.Fatalf("entry = %+v, %v", e, ok)
+	}
+	if n := c.Prune(func(CacheEntry) bool { return true }); n != 1 || len(c.Entries()) != 0 {
+		t.Fatalf("pruned %d, left %d", n, len(c.Entries()))
+	}
+}
+
+func TestCacheEntry_Fresh(t *testing.T) {
+	now := time.Now()
+	pass := CacheEntry{OK: true, CheckedAt: now.Add(-2 * time.Hour)}
+	fail := CacheEntry{OK: false, CheckedAt: now.Add(-2 * time.Hour)}
+	if !pass.Fresh(now, 0, 0) || fail.Fresh(now, 0, 0) {
+		t.Fatal("default TTLs: pass should be fresh and failure stale")
+	}
+	if pass.Fresh(now, time.Hour, 0) || !fail.Fresh(now, 0, 3*time.Hour) {
+		t.Fatal("configured TTLs not applied")
+	}
+}
+
+func TestCheckCached_Revalidate(t *testing.T) {
+	var requests, conditional int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			conditional++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Content-Type", "text/plain")
+	}))
+	defer srv.Close()
+	client := srv.Client()
+	client.Timeout = 2 * time.Second
+	cfg := Config{CacheTTL: time.Hour}
+	hc := newHTTPChecker(client, cfg)
+	cache, err := OpenCache(filepath.Join(t.TempDir(), "results.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	target := srv.URL + "/page"
+
+	r := checkCached(context.Background(), cache, cfg, target, hc.check)
+	if !r.OK || r.CacheHit || requests != 1 {
+		t.Fatalf("first check: ok=%v hit=%v requests=%d", r.OK, r.CacheHit, requests)
+	}
+	r = checkCached(context.Background(), cache, cfg, target, hc.check)
+	if !r.OK || !r.CacheHit || requests != 1 {
+		t.Fatalf("fresh entry: ok=%v hit=%v requests=%d", r.OK, r.CacheHit, requests)
+	}
+
+	// Age the entry past its TTL; the next check revalidates with the ETag
+	e, _ := cache.Get(target)
+	e.CheckedAt = time.Now().Add(-2 * time.Hour)
+	cache.Put(e)
+	r = checkCached(context.Background(), cache, cfg, target, hc.check)
+	if !r.OK || !r.CacheHit || r.Status != 200 || conditional != 1 {
+		t.Fatalf("revalidation: ok=%v hit=%v status=%d conditional=%d", r.OK, r.CacheHit, r.Status, conditional)
+	}
+	if e, _ := cache.Get(target); time.Since(e.CheckedAt) > time.Minute {
+		t.Fatalf("revalidation did not refresh the entry: %v", e.CheckedAt)
+	}
+}
+
+func TestCheckCached_ConfigChangeInvalidates(t *testing.T) {
+	cache, err := OpenCache(filepath.Join(t.TempDir(), "results.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checks := 0
+	check := func(context.Context, string) Result {
+		checks++
+		return Result{OK: true, Status: 200, Method: "HEAD"}
+	}
+	target := "https://example.com/page"
+	cfg := Config{CacheTTL: time.Hour}
+
+	checkCached(context.Background(), cache, cfg, target, check)
+	if r := checkCached(context.Background(), cache, cfg, target, check); !r.CacheHit || checks != 1 {
+		t.Fatalf("same settings: hit=%v checks=%d", r.CacheHit, checks)
+	}
+
+	for _, changed := range []Config{
+		{CacheTTL: time.Hour, StatusPolicies: []StatusPolicy{{Host: "example.com", Reject: []string{"200"}}}},
+		{CacheTTL: time.Hour, Soft404: Soft404Config{Enabled: true}},
+		{CacheTTL: time.Hour, Auth: []HostAuth{{Match: "example.com", Bearer: "secret"}}},
+	} {
+		before := checks
+		if r := checkCached(context.Background(), cache, changed, target, check); r.CacheHit || checks != before+1 {
+			t.Fatalf("%+v: hit=%v, want a recheck", changed, r.CacheHit)
+		}
+	}
+
+	// Only where credentials apply counts, not their values
+	a := cacheFingerprint(Config{Auth: []HostAuth{{Match: "example.com", Bearer: "one"}}})
+	b := cacheFingerprint(Config{Auth: []HostAuth{{Match: "example.com", Bearer: "two"}}})
+	if a != b {
+		t.Fatalf("fingerprint depends on the token value")
+	}
+}
+
+func TestCheckCached_SlowNotStored(t *testing.T) {
+	cache, err := OpenCache(filepath.Join(t.TempDir(), "cache.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	check := func(context.Context, string) Result {
+		return Result{OK: true, Status: 200, Method: "GET", ErrorKind: ErrorKindSlow, Warnings: []string{
+			"permanent redirect",
+			slowWarningPrefix + "3s (threshold 1s)",
+		}}
+	}
+	target := "https://example.com/slow"
+	cfg := Config{CacheTTL: time.Hour, SlowThreshold: time.Second}
+	if r := checkCached(context.Background(), cache, cfg, target, check); r.ErrorKind != ErrorKindSlow {
+		t.Fatalf("fresh check lost its slow warning: %+v", r)
+	}
+	r := checkCached(context.Background(), cache, cfg, target, check)
+	if !r.CacheHit || r.ErrorKind != "" || len(r.Warnings) != 1 || r.Warnings[0] != "permanent redirect" {
+		t.Fatalf("cache hit: hit=%v kind=%q warnings=%v, want only the redirect warning", r.CacheHit, r.ErrorKind, r.Warnings)
+	}
+}
@@ -4,35 +4,48 @@ import (
 	"context"
 	"net"
 	"net/http"
-	"sort"
+	"sync"
 	"time"
+
+	"slinky/internal/fsurls"
 )
 
-// CheckURLs performs concurrent GET requests for each URL and emits Result events.
-// sources maps URL -> list of file paths where it was found.
-func CheckURLs(ctx context.Context, urls []string, sources map[string][]string, out chan<- Result, stats chan<- Stats, cfg Config) {
+// CheckURLs checks each URL concurrently with the checker registered for its
+// scheme and emits Result events.
+// sources maps URL -> occurrences of it in the scanned files.
+func CheckURLs(ctx context.Context, urls []string, sources map[string][]fsurls.Source, out chan<- Result, stats chan<- Stats, cfg Config) {
 	defer close(out)
 
 	// Build HTTP client similar to crawler
+	connectTimeout, tlsTimeout, headerTimeout := cfg.ConnectTimeout, cfg.TLSTimeout, cfg.HeaderTimeout
+	if connectTimeout <= 0 {
+		connectTimeout = 2 * time.Second
+	}
+	if tlsTimeout <= 0 {
+		tlsTimeout = 5 * time.Second
+	}
+	if headerTimeout <= 0 {
+		headerTimeout = cfg.RequestTimeout
+	}
 	transport := &http.Transport{
 		Proxy:                 http.ProxyFromEnvironment,
-		DialContext:           (&net.Dialer{Timeout: 2 * time.Second, KeepAlive: 30 * time.Second}).DialContext,
-		TLSHandshakeTimeout:   5 * time.Second,
+		DialContext:           (&net.Dialer{Timeout: connectTimeout, KeepAlive: 30 * time.Second}).DialContext,
+		TLSHandshakeTimeout:   tlsTimeout,
 		ExpectContinueTimeout: 1 * time.Second,
 		MaxIdleConns:          cfg.MaxConcurrency * 2,
 		MaxIdleConnsPerHost:   cfg.MaxConcurrency,
 		MaxConnsPerHost:       cfg.MaxConcurrency,
 		IdleConnTimeout:       30 * time.Second,
-		ResponseHeaderTimeout: cfg.RequestTimeout,
+		ResponseHeaderTimeout: headerTimeout,
 	}
-	client := &http.Client{Timeout: cfg.RequestTimeout, Transport: transport}
+	// RequestTimeout is applied by the rate limiter once a request may go, not
+	// as Client.Timeout, which would also count the wait for a slot
+	client := &http.Client{Transport: newRateLimitTransport(newAuthTransport(newTLSTransport(transport, cfg.TLS), cfg.Auth), cfg)}
+	schemes := newSchemeRegistry(cfg, client)
 
-	type job struct{ url string }
-	jobs := make(chan job, len(urls))
-	done := make(chan struct{})
-
-	// Seed jobs
+	// Dedupe
 	unique := make(map[string]struct{}, len(urls))
+	var queue []string
 	for _, u := range urls {
 		if u == "" {
 			continue
@@ -41,33 +54,32 @@ func CheckURLs(ctx context.Context, urls []string, sources map[string][]string,
 			continue
 		}
 		unique[u] = struct{}{}
-		jobs <- job{url: u}
+		queue = append(queue, u)
 	}
-	close(jobs)
 
 	concurrency := cfg.MaxConcurrency
 	if concurrency <= 0 {
 		concurrency = 8
 	}
-	processed := 0
-	pending := len(unique)
+	sched := newScheduler(ctx, queue, cfg, concurrency)
+	defer sched.close()
+	var (
+		mu        sync.Mutex
+		processed int
+		pending   = len(queue)
+		wg        sync.WaitGroup
+	)
 
 	worker := func() {
-		for j := range jobs {
-			select {
-			case <-ctx.Done():
+		defer wg.Done()
+		for {
+			u, q, ok := sched.acquire()
+			if !ok {
 				return
-			default:
-			}
-			ok, status, resp, err := fetchWithMethod(ctx, client, http.MethodGet, j.url)
-			if resp != nil && resp.Body != nil {
-				resp.Body.Close()
-			}
-			// Treat 401/403/408/429 as valid links
-			if status == http.StatusUnauthorized || status == http.StatusForbidden || status == http.StatusRequestTimeout || status == http.StatusTooManyRequests {
-				ok = true
-				err = nil
 			}
+			target := ApplyRewrites(cfg.Rewrites, u)
+			res := checkCached(ctx, cfg.Cache, cfg, target, schemes.check)
+			sched.release(q)
 			// Check context before sending result
 			select {
 			case <-ctx.Done():
@@ -75,43 +87,64 @@ func CheckURLs(ctx context.Context, urls []string, sources map[string][]string,
 			default:
 			}
 
-			var srcs []string
+			var srcs []fsurls.Source
 			if sources != nil {
-				srcs = sources[j.url]
+				srcs = sources[u]
+			}
+
+			var rewritten string
+			if target != u {
+				rewritten = target
 			}
 
 			// Send result with context check
 			select {
-			case out <- Result{URL: j.url, OK: ok, Status: status, Err: err, ErrMsg: errString(err), Method: http.MethodGet, Sources: cloneAndSort(srcs)}:
+			case out <- finishResult(res, u, rewritten, srcs):
 			case <-ctx.Done():
 				return
 			}
 
+			mu.Lock()
 			processed++
 			pending--
+			st := Stats{Pending: pending, Processed: processed}
+			mu.Unlock()
 			if stats != nil {
 				select {
-				case stats <- Stats{Pending: pending, Processed: processed}:
+				case stats <- st:
 				default:
 				}
 			}
 		}
-		done <- struct{}{}
 	}
 
+	wg.Add(concurrency)
 	for i := 0; i < concurrency; i++ {
 		go worker()
 	}
-	for i := 0; i < concurrency; i++ {
-		<-done
+	wg.Wait()
+}
+
+// finishResult fills the fields every checker shares.
+func finishResult(res Result, url, rewritten string, srcs []fsurls.Source) Result {
+	target := url
+	if rewritten != "" {
+		target = rewritten
 	}
+	res = checkContentType(res, target, srcs)
+	res.URL = url
+	res.RewrittenURL = rewritten
+	res.ErrMsg = errString(res.Err)
+	res.Sources = cloneAndSort(srcs)
+	res.Severity = severityOf(res)
+	return res
 }
 
-func cloneAndSort(in []string) []string {
+func cloneAndSort(in []fsurls.Source) []fsurls.Source {
 	if len(in) == 0 {
 		return nil
 	}
-	out := append([]string(nil), in...)
-	sort.Strings(out)
+	out := append([]fsurls.Source(nil), in...)
+	fsurls.SortSources(out)
 	return out
 }
@@ -4,6 +4,8 @@ import (
 	"context"
 	"testing"
 	"time"
+
+	"slinky/internal/fsurls"
 )
 
 // This test exercises CheckURLs with a mix of known-good and invalid URLs.
@@ -16,11 +18,11 @@ func TestCheckURLs_Basic(t *testing.T) {
 		"https://this-domain-does-not-exist-123456789.com", // NXDOMAIN/nonexistent
 	}
 
-	sources := map[string][]string{
-		"https://example.com":                              {"test files/test2.txt"},
-		"https://en.wikipedia.org/wiki/Main_Page":          {"test files/test5.html"},
-		"http://example..com":                              {"test files/test5.html"},
-		"https://this-domain-does-not-exist-123456789.com": {"test files/test5.html"},
+	sources := map[string][]fsurls.Source{
+		"https://example.com":                              {{File: "test files/test2.txt"}},
+		"https://en.wikipedia.org/wiki/Main_Page":          {{File: "test files/test5.html"}},
+		"http://example..com":                              {{File: "test files/test5.html"}},
+		"https://this-domain-does-not-exist-123456789.com": {{File: "test files/test5.html"}},
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -51,3 +53,21 @@ func TestCheckURLs_Basic(t *testing.T) {
 		t.Fatalf("expected at least one failure result")
 	}
 }
+
+func TestSeverityOf(t *testing.T) {
+	cases := []struct {
+		res  Result
+		want Severity
+	}{
+		{Result{OK: true}, SeverityOK},
+		{Result{OK: true, Warnings: []string{"permanent redirect"}}, SeverityWarn},
+		{Result{OK: false, Status: 404}, SeverityError},
+		{Result{OK: false, Warnings: []string{"permanent redirect"}}, SeverityError},
+		{Result{Skipped: true, Err: simpleError("unsupported scheme: x")}, SeverityOK},
+	}
+	for i, c := range cases {
+		if got := severityOf(c.res); got != c.want {
+			t.Errorf("case %d: severity %q, want %q", i, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package web
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"slinky/internal/fsurls"
+)
+
+// contentExpectation is the kind of resource a link's context calls for.
+type contentExpectation struct {
+	what    string // e.g. "an image"
+	reason  string // the context or extension that set the expectation
+	accepts func(mediaType string) bool
+}
+
+var (
+	expectImage = contentExpectation{what: "an image", accepts: func(mt string) bool {
+		return strings.HasPrefix(mt, "image/")
+	}}
+	expectScript = contentExpectation{what: "JavaScript", accepts: func(mt string) bool {
+		switch mt {
+		case "application/javascript", "text/javascript", "application/x-javascript", "application/ecmascript", "text/ecmascript":
+			return true
+		}
+		return false
+	}}
+	expectStylesheet = contentExpectation{what: "CSS", accepts: func(mt string) bool {
+		return mt == "text/css"
+	}}
+	// Raw file hosts commonly serve JSON as text/plain
+	expectJSON = contentExpectation{what: "JSON", accepts: func(mt string) bool {
+		return mt == "application/json" || mt == "text/json" || strings.HasSuffix(mt, "+json") || mt == "text/plain"
+	}}
+)
+
+// extensionContexts are the contexts in which a URL is fetched as data rather
+// than followed by a reader. Only there does a .json extension set an
+// expectation: a Markdown link to a .json file on GitHub shows an HTML page.
+var extensionContexts = map[string]bool{
+	fsurls.ContextSrc:    true,
+	fsurls.ContextQuoted: true,
+	fsurls.ContextRef:    true,
+}
+
+// contentExpectations derives what target should serve from the contexts it
+// was found in and, for data contexts, its file extension.
+func contentExpectations(target string, srcs []fsurls.Source) []contentExpectation {
+	var (
+		out   []contentExpectation
+		byExt bool
+		seen  = make(map[string]bool)
+	)
+	add := func(e contentExpectation, reason string) {
+		if !seen[e.what] {
+			seen[e.what] = true
+			e.reason = reason
+			out = append(out, e)
+		}
+	}
+	for _, src := range srcs {
+		switch src.Context {
+		case fsurls.ContextMarkdownImage, fsurls.ContextImgSrc:
+			add(expectImage, src.Context)
+		case fsurls.ContextScriptSrc:
+			add(expectScript, src.Context)
+		case fsurls.ContextStylesheet:
+			add(expectStylesheet, src.Context)
+		}
+		byExt = byExt || extensionContexts[src.Context]
+	}
+	if !byExt {
+		return out
+	}
+	if u, err := url.Parse(target); err == nil && strings.EqualFold(path.Ext(u.Path), ".json") {
+		add(expectJSON, ".json")
+	}
+	return out
+}
+
+// checkContentType compares the Content-Type of a working HTTP link with what
+// its contexts expect. An HTML page in place of an asset, typically a login
+// or error page, fails the link; other mismatches are warnings.
+func checkContentType(res Result, target string, srcs []fsurls.Source) Result {
+	if !res.OK || res.ContentType == "" || (res.Method != "HEAD" && res.Method != "GET") {
+		return res
+	}
+	mt := mediaType(res.ContentType)
+	for _, e := range contentExpectations(target, srcs) {
+		if e.accepts(mt) {
+			continue
+		}
+		msg := fmt.Sprintf("content type %s, expected %s (%s)", mt, e.what, e.reason)
+		if mt == "text/html" || mt == "application/xhtml+xml" {
+			res.OK = false
+			res.Err = simpleError(msg)
+			res.ErrorKind = ErrorKindContentType
+			return res
+		}
+		res.Warnings = append(res.Warnings, msg)
+		if res.ErrorKind == "" {
+			res.ErrorKind = ErrorKindContentType
+		}
+	}
+	return res
+}
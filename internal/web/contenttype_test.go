@@ -0,0 +1,39 @@
+package web
+
+import (
+	"testing"
+
+	"slinky/internal/fsurls"
+)
+
+func TestCheckContentType(t *testing.T) {
+	src := func(context string) []fsurls.Source { return []fsurls.Source{{File: "doc.md", Context: context}} }
+	cases := []struct {
+		name        string
+		target      string
+		contentType string
+		srcs        []fsurls.Source
+		ok          bool
+		warnings    int
+	}{
+		{"image", "https://example.com/logo.png", "image/png", src(fsurls.ContextMarkdownImage), true, 0},
+		{"login page for image", "https://example.com/logo.png", "text/html; charset=utf-8", src(fsurls.ContextImgSrc), false, 0},
+		{"octet-stream image", "https://example.com/logo", "application/octet-stream", src(fsurls.ContextMarkdownImage), true, 1},
+		{"script", "https://example.com/app.js", "text/javascript", src(fsurls.ContextScriptSrc), true, 0},
+		{"stylesheet as plain text", "https://example.com/site.css", "text/plain", src(fsurls.ContextStylesheet), true, 1},
+		{"json", "https://example.com/data.json", "application/vnd.api+json", src(fsurls.ContextMarkdownLink), true, 0},
+		{"json served as html", "https://example.com/data.json?v=2", "text/html", src(fsurls.ContextQuoted), false, 0},
+		{"json blob page", "https://github.com/o/r/blob/main/package.json", "text/html; charset=utf-8", src(fsurls.ContextMarkdownLink), true, 0},
+		{"json blob page, bare", "https://github.com/o/r/blob/main/package.json", "text/html", src(fsurls.ContextBare), true, 0},
+		{"plain link", "https://example.com/page", "text/html", src(fsurls.ContextMarkdownLink), true, 0},
+	}
+	for _, c := range cases {
+		res := checkContentType(Result{OK: true, Status: 200, Method: "GET", ContentType: c.contentType}, c.target, c.srcs)
+		if res.OK != c.ok || len(res.Warnings) != c.warnings {
+			t.Errorf("%s: ok=%v warnings=%v err=%v, want ok=%v with %d warnings", c.name, res.OK, res.Warnings, res.Err, c.ok, c.warnings)
+		}
+		if (!c.ok || c.warnings > 0) && res.ErrorKind != ErrorKindContentType {
+			t.Errorf("%s: kind %q", c.name, res.ErrorKind)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package web
+
+import (
+	"encoding/base64"
+	"mime"
+	"net/url"
+	"strings"
+)
+
+// checkDataURL verifies that a data: URL (RFC 2397) has a valid media type and
+// a payload that decodes.
+func checkDataURL(raw string) (bool, error) {
+	meta, payload, ok := strings.Cut(raw[len("data:"):], ",")
+	if !ok {
+		return false, simpleError("missing ',' before data")
+	}
+	isBase64 := false
+	if before, found := strings.CutSuffix(meta, ";base64"); found {
+		meta, isBase64 = before, true
+	}
+	if meta != "" && !strings.HasPrefix(meta, ";") {
+		if _, _, err := mime.ParseMediaType(meta); err != nil {
+			return false, simpleError("invalid media type: " + meta)
+		}
+	}
+	data, err := url.PathUnescape(payload)
+	if err != nil {
+		return false, simpleError("invalid percent-encoding")
+	}
+	if isBase64 {
+		// Whitespace is common in hand-wrapped data URLs and ignored by browsers
+		data = strings.Join(strings.Fields(data), "")
+		if _, err := base64.StdEncoding.DecodeString(data); err != nil {
+			if _, rerr := base64.RawStdEncoding.DecodeString(data); rerr != nil {
+				return false, simpleError("invalid base64 payload")
+			}
+		}
+	}
+	return true, nil
+}
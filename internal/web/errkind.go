@@ -0,0 +1,176 @@
+package web
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+)
+
+// ErrorKind classifies why a link failed or was flagged.
+type ErrorKind string
+
+const (
+	// ErrorKindDNS marks a hostname that did not resolve.
+	ErrorKindDNS ErrorKind = "dns"
+	// ErrorKindTimeoutConnect marks a timeout before a connection was made.
+	ErrorKindTimeoutConnect ErrorKind = "timeout-connect"
+	// ErrorKindTimeoutTLS marks a timeout during the TLS handshake.
+	ErrorKindTimeoutTLS ErrorKind = "timeout-tls"
+	// ErrorKindTimeoutHeader marks a server that accepted the request but
+	// sent no response headers in time.
+	ErrorKindTimeoutHeader ErrorKind = "timeout-header"
+	// ErrorKindTimeout is a timeout in an unknown phase.
+	ErrorKindTimeout ErrorKind = "timeout"
+	// ErrorKindRefused marks a connection refused by the host.
+	ErrorKindRefused ErrorKind = "refused"
+	// ErrorKindReset marks a connection reset or closed by the server.
+	ErrorKindReset ErrorKind = "reset"
+	// ErrorKindNetwork is any other transport failure.
+	ErrorKindNetwork ErrorKind = "network"
+	// ErrorKindCanceled marks a check interrupted by the run ending.
+	ErrorKindCanceled ErrorKind = "canceled"
+	// ErrorKindRateLimitWait marks a request abandoned while waiting for its
+	// slot under a configured rps limit, before anything was sent.
+	ErrorKindRateLimitWait ErrorKind = "rate-limit-wait"
+	// ErrorKindInvalidURL marks a URL that could not be requested at all.
+	ErrorKindInvalidURL ErrorKind = "invalid-url"
+	// ErrorKindHTTPStatus marks a response whose status was not accepted.
+	ErrorKindHTTPStatus ErrorKind = "http-status"
+	// ErrorKindTooManyRedirects marks a chain longer than the limit.
+	ErrorKindTooManyRedirects ErrorKind = "too-many-redirects"
+	// ErrorKindRedirectLoop marks a chain that revisits a URL.
+	ErrorKindRedirectLoop ErrorKind = "redirect-loop"
+	// ErrorKindContentType marks a Content-Type that does not fit the
+	// link's context, e.g. HTML where an image was expected.
+	ErrorKindContentType ErrorKind = "content-type-mismatch"
+	// ErrorKindSlow marks a link that responded slower than the threshold.
+	ErrorKindSlow ErrorKind = "slow"
+	// ErrorKindHTTPSUpgrade marks an http:// link whose https:// form
+	// serves the same resource.
+	ErrorKindHTTPSUpgrade ErrorKind = "https-upgrade"
+	// ErrorKindJSONPointer marks a document whose JSON pointer fragment, as
+	// in a remote $ref, does not resolve.
+	ErrorKindJSONPointer ErrorKind = "json-pointer"
+	// ErrorKindSoft404 marks a missing page served with a success status.
+	ErrorKindSoft404 ErrorKind = "soft-404"
+	// ErrorKindGitHubNotFound marks a GitHub repository or account that does
+	// not exist, or that the token cannot see (the API does not distinguish
+	// the two).
+	ErrorKindGitHubNotFound ErrorKind = "github-not-found"
+	// ErrorKindGitHubGone marks a reachable GitHub repository whose ref, path,
+	// line range, issue or release is missing.
+	ErrorKindGitHubGone ErrorKind = "github-gone"
+	// ErrorKindGitHubNoAccess marks a link the GitHub API refused to check
+	// (401/403).
+	ErrorKindGitHubNoAccess ErrorKind = "github-no-access"
+	// ErrorKindTLS is a TLS failure not covered by a more specific kind.
+	ErrorKindTLS ErrorKind = "tls"
+	// ErrorKindTLSExpired marks a certificate outside its validity period.
+	ErrorKindTLSExpired ErrorKind = "tls-expired"
+	// ErrorKindTLSUnknownAuthority marks a certificate from an untrusted CA.
+	ErrorKindTLSUnknownAuthority ErrorKind = "tls-unknown-authority"
+	// ErrorKindTLSHostname marks a certificate not valid for the host.
+	ErrorKindTLSHostname ErrorKind = "tls-hostname-mismatch"
+)
+
+// kindError is an error classified with an ErrorKind and a readable message
+// that replaces the transport's error text.
+type kindError struct {
+	kind ErrorKind
+	msg  string
+	err  error
+}
+
+func (e *kindError) Error() string { return e.msg }
+func (e *kindError) Unwrap() error { return e.err }
+
+// errorKindOf returns the kind attached to err, if any.
+func errorKindOf(err error) ErrorKind {
+	var ke *kindError
+	if errors.As(err, &ke) {
+		return ke.kind
+	}
+	return ""
+}
+
+// classifyTLS recognizes certificate and handshake failures in err.
+func classifyTLS(err error) (*kindError, bool) {
+	var (
+		invalid  x509.CertificateInvalidError
+		unknown  x509.UnknownAuthorityError
+		hostname x509.HostnameError
+		verify   *tls.CertificateVerificationError
+		record   tls.RecordHeaderError
+		alert    tls.AlertError
+	)
+	switch {
+	case errors.As(err, &invalid) && invalid.Reason == x509.Expired:
+		return &kindError{ErrorKindTLSExpired, "tls: certificate has expired or is not yet valid", err}, true
+	case errors.As(err, &unknown):
+		return &kindError{ErrorKindTLSUnknownAuthority, "tls: certificate signed by unknown authority", err}, true
+	case errors.As(err, &hostname):
+		msg := "tls: certificate is not valid for this host"
+		if hostname.Host != "" {
+			msg = fmt.Sprintf("tls: certificate is not valid for %s", hostname.Host)
+		}
+		return &kindError{ErrorKindTLSHostname, msg, err}, true
+	case errors.As(err, &invalid):
+		return &kindError{ErrorKindTLS, "tls: " + invalid.Error(), err}, true
+	case errors.As(err, &verify):
+		return &kindError{ErrorKindTLS, "tls: " + verify.Err.Error(), err}, true
+	case errors.As(err, &alert):
+		return &kindError{ErrorKindTLS, "tls: handshake failed: " + alert.Error(), err}, true
+	case errors.As(err, &record):
+		return &kindError{ErrorKindTLS, "tls: server did not speak TLS", err}, true
+	}
+	return nil, false
+}
+
+// classifyError turns a failed request into a kindError. trace, if set,
+// tells which phase a timeout happened in.
+func classifyError(err error, trace *requestTrace) *kindError {
+	var ke *kindError
+	if errors.As(err, &ke) {
+		return ke
+	}
+	if ke, ok := classifyTLS(err); ok {
+		return ke
+	}
+	var (
+		dnsErr *net.DNSError
+		netErr net.Error
+	)
+	switch {
+	case errors.As(err, &dnsErr):
+		switch {
+		case dnsErr.IsNotFound:
+			return &kindError{ErrorKindDNS, "dns: no such host " + dnsErr.Name, err}
+		case dnsErr.IsTimeout:
+			return &kindError{ErrorKindDNS, "dns: lookup of " + dnsErr.Name + " timed out", err}
+		}
+		return &kindError{ErrorKindDNS, "dns: lookup of " + dnsErr.Name + " failed: " + dnsErr.Err, err}
+	case errors.Is(err, context.Canceled):
+		return &kindError{ErrorKindCanceled, "canceled", err}
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		kind := trace.timeoutKind()
+		msg := map[ErrorKind]string{
+			ErrorKindTimeoutConnect: "timeout: connecting",
+			ErrorKindTimeoutTLS:     "timeout: TLS handshake",
+			ErrorKindTimeoutHeader:  "timeout: awaiting response headers",
+			ErrorKindTimeout:        "timeout",
+		}[kind]
+		return &kindError{kind, msg, err}
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return &kindError{ErrorKindRefused, "connection refused", err}
+	case errors.Is(err, syscall.ECONNRESET):
+		return &kindError{ErrorKindReset, "connection reset by server", err}
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return &kindError{ErrorKindReset, "connection closed by server", err}
+	}
+	return &kindError{ErrorKindNetwork, err.Error(), err}
+}
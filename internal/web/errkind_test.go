@@ -0,0 +1,71 @@
+package web
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestClassifyError(t *testing.T) {
+	cases := []struct {
+		err   error
+		trace *requestTrace
+		kind  ErrorKind
+	}{
+		{&net.DNSError{Name: "nope.invalid", Err: "no such host", IsNotFound: true}, nil, ErrorKindDNS},
+		{&net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}, nil, ErrorKindRefused},
+		{&net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNRESET)}, nil, ErrorKindReset},
+		{context.DeadlineExceeded, &requestTrace{}, ErrorKindTimeoutConnect},
+		{context.DeadlineExceeded, &requestTrace{tlsStarted: true}, ErrorKindTimeoutTLS},
+		{context.DeadlineExceeded, &requestTrace{gotConn: true}, ErrorKindTimeoutHeader},
+		{context.Canceled, nil, ErrorKindCanceled},
+		{errTooManyRedirects, nil, ErrorKindTooManyRedirects},
+	}
+	for _, c := range cases {
+		if got := classifyError(c.err, c.trace); got.kind != c.kind {
+			t.Errorf("classifyError(%v) = %s, want %s", c.err, got.kind, c.kind)
+		}
+	}
+}
+
+func TestCheckHTTP_ErrorKinds(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/slow":
+			time.Sleep(300 * time.Millisecond)
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	// A port that was just released refuses connections
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed := "http://" + ln.Addr().String() + "/"
+	ln.Close()
+
+	client := &http.Client{Timeout: 100 * time.Millisecond}
+	cases := []struct {
+		url    string
+		status int
+		kind   ErrorKind
+	}{
+		{srv.URL + "/missing", 404, ErrorKindHTTPStatus},
+		{srv.URL + "/slow", 0, ErrorKindTimeoutHeader},
+		{closed, 0, ErrorKindRefused},
+	}
+	for _, c := range cases {
+		r := checkHTTP(context.Background(), client, Config{}, c.url)
+		if r.OK || r.Status != c.status || r.ErrorKind != c.kind {
+			t.Errorf("%s: ok=%v status=%d kind=%q err=%v, want status=%d kind=%s", c.url, r.OK, r.Status, r.ErrorKind, r.Err, c.status, c.kind)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package web
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"slinky/internal/jsonpointer"
+)
+
+func isFileURL(raw string) bool {
+	return strings.HasPrefix(strings.ToLower(raw), "file:")
+}
+
+// checkFileURL verifies that a file: target exists and, when the fragment is
+// a JSON pointer (as in OpenAPI $refs), that the pointer resolves in the file.
+// Opaque targets ("file:docs/api.yaml") are relative to the working directory.
+func checkFileURL(raw string) (bool, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false, err
+	}
+	p := filepath.FromSlash(u.Path)
+	if u.Opaque != "" {
+		opaque, err := url.PathUnescape(u.Opaque)
+		if err != nil {
+			return false, err
+		}
+		p = filepath.FromSlash(opaque)
+	}
+	st, err := os.Stat(p)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, simpleError("file not found")
+		}
+		return false, err
+	}
+	switch {
+	case u.Fragment == "" || st.IsDir():
+	case strings.HasPrefix(u.Fragment, "/"):
+		if err := jsonpointer.ResolveFile(p, u.Fragment); err != nil {
+			return false, err
+		}
+	case isAsciiDoc(p):
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return false, err
+		}
+		if !adocHasAnchor(string(b), u.Fragment) {
+			return false, simpleError("anchor not found: " + u.Fragment)
+		}
+	}
+	return true, nil
+}
+
+var adocAnchorRegex = regexp.MustCompile(`\[\[([^\[\],\s]+)(?:,[^\]]*)?\]\]|\[#([^\].,%\s]+)|anchor:([^\[\s]+)\[|(?m)^:id:[ \t]*(\S+)`)
+var adocSectionRegex = regexp.MustCompile(`(?m)^={1,6}[ \t]+(.+?)[ \t]*$`)
+var adocIDInvalidRegex = regexp.MustCompile(`[^a-z0-9]+`)
+
+func isAsciiDoc(p string) bool {
+	switch strings.ToLower(filepath.Ext(p)) {
+	case ".adoc", ".asciidoc", ".asc":
+		return true
+	}
+	return false
+}
+
+// adocHasAnchor reports whether id is declared in an AsciiDoc document, either
+// explicitly ([[id]], [#id], anchor:id[]) or as an auto-generated section id
+// (Asciidoctor's default "_" prefix and "_" separator).
+func adocHasAnchor(content, id string) bool {
+	for _, m := range adocAnchorRegex.FindAllStringSubmatch(content, -1) {
+		for _, g := range m[1:] {
+			if g == id {
+				return true
+			}
+		}
+	}
+	for _, m := range adocSectionRegex.FindAllStringSubmatch(content, -1) {
+		auto := "_" + strings.Trim(adocIDInvalidRegex.ReplaceAllString(strings.ToLower(m[1]), "_"), "_")
+		if auto == id {
+			return true
+		}
+	}
+	return false
+}
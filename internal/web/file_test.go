@@ -0,0 +1,41 @@
+package web
+
+import (
+	"net/url"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckFileURL(t *testing.T) {
+	abs, err := filepath.Abs(filepath.Join("..", "..", "testdata"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fileURL := func(name, frag string) string {
+		return (&url.URL{Scheme: "file", Path: filepath.ToSlash(filepath.Join(abs, filepath.FromSlash(name))), Fragment: frag}).String()
+	}
+
+	cases := []struct {
+		url string
+		ok  bool
+	}{
+		{fileURL("openapi/schemas.yaml", "/Pet"), true},
+		{fileURL("openapi/schemas.yaml", "/Pet/properties/name"), true},
+		{fileURL("openapi/schemas.yaml", "/Missing"), false},
+		{fileURL("openapi/errors.yaml", "/Error"), false},
+		{fileURL("markup/manual.adoc", "setup"), true},
+		{fileURL("markup/manual.adoc", "_getting_started"), true},
+		{fileURL("markup/manual.adoc", "nowhere"), false},
+		{fileURL("markup/guide.rst", ""), true},
+		// Relative to the working directory, as the extractors emit them
+		{"file:../../testdata/openapi/schemas.yaml#/Pet", true},
+		{"file:../../testdata/openapi/schemas.yaml#/Missing", false},
+		{"file:../../testdata/openapi/missing.yaml", false},
+	}
+	for _, c := range cases {
+		ok, err := checkFileURL(c.url)
+		if ok != c.ok {
+			t.Fatalf("checkFileURL(%q) ok=%v err=%v, want ok=%v", c.url, ok, err, c.ok)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package web
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// checkFTPURL logs in to an ftp:// server (anonymously unless the URL carries
+// credentials) and verifies the path: CWD for directories, SIZE for files,
+// falling back to NLST over a passive data connection when SIZE is not
+// supported. The returned status is the last FTP reply code.
+func checkFTPURL(ctx context.Context, raw string, timeout time.Duration) (bool, int, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false, 0, err
+	}
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), "21")
+	}
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return false, 0, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	tp := textproto.NewConn(conn)
+
+	code, _, err := tp.ReadResponse(220)
+	if err != nil {
+		return false, code, err
+	}
+	user, pass := "anonymous", "anonymous@"
+	if u.User != nil {
+		user = u.User.Username()
+		if p, ok := u.User.Password(); ok {
+			pass = p
+		}
+	}
+	code, err = ftpCmd(tp, "USER "+user)
+	if err == nil && code == 331 {
+		code, err = ftpCmd(tp, "PASS "+pass)
+	}
+	if err != nil {
+		return false, code, err
+	}
+	if code != 230 && code != 202 {
+		return false, code, fmt.Errorf("login failed (%d)", code)
+	}
+	defer ftpCmd(tp, "QUIT")
+
+	p := u.Path
+	if p == "" || p == "/" {
+		return true, code, nil
+	}
+	if strings.HasSuffix(p, "/") {
+		code, err = ftpCmd(tp, "CWD "+p)
+		if err != nil {
+			return false, code, err
+		}
+		if code != 250 {
+			return false, code, fmt.Errorf("directory not found (%d)", code)
+		}
+		return true, code, nil
+	}
+	code, err = ftpCmd(tp, "SIZE "+p)
+	if err != nil {
+		return false, code, err
+	}
+	switch {
+	case code == 213:
+		return true, code, nil
+	case code == 550:
+		return false, code, fmt.Errorf("file not found (%d)", code)
+	}
+	// SIZE unsupported: list the parent directory instead
+	return ftpListContains(ctx, tp, conn, p)
+}
+
+func ftpCmd(tp *textproto.Conn, cmd string) (int, error) {
+	id, err := tp.Cmd("%s", cmd)
+	if err != nil {
+		return 0, err
+	}
+	tp.StartResponse(id)
+	defer tp.EndResponse(id)
+	code, _, err := tp.ReadResponse(0)
+	if _, isProto := err.(*textproto.Error); isProto {
+		// Unexpected codes are for the caller to interpret
+		err = nil
+	}
+	return code, err
+}
+
+func ftpListContains(ctx context.Context, tp *textproto.Conn, ctrl net.Conn, p string) (bool, int, error) {
+	id, err := tp.Cmd("PASV")
+	if err != nil {
+		return false, 0, err
+	}
+	tp.StartResponse(id)
+	code, msg, err := tp.ReadResponse(227)
+	tp.EndResponse(id)
+	if err != nil {
+		return false, code, err
+	}
+	dataAddr, err := parsePASV(msg, ctrl.RemoteAddr())
+	if err != nil {
+		return false, code, err
+	}
+	var d net.Dialer
+	dc, err := d.DialContext(ctx, "tcp", dataAddr)
+	if err != nil {
+		return false, code, err
+	}
+	defer dc.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = dc.SetDeadline(deadline)
+	}
+	id, err = tp.Cmd("NLST %s", path.Dir(p))
+	if err != nil {
+		return false, 0, err
+	}
+	tp.StartResponse(id)
+	defer tp.EndResponse(id)
+	code, _, err = tp.ReadResponse(1)
+	if err != nil {
+		return false, code, err
+	}
+	listing, _ := io.ReadAll(dc)
+	dc.Close()
+	code, _, err = tp.ReadResponse(2)
+	if err != nil {
+		return false, code, err
+	}
+	base := path.Base(p)
+	for _, name := range strings.Split(string(listing), "\n") {
+		name = strings.TrimSpace(name)
+		if name == base || path.Base(name) == base {
+			return true, code, nil
+		}
+	}
+	return false, code, simpleError("file not found in listing")
+}
+
+// parsePASV extracts the data address from a 227 reply. The advertised IP is
+// replaced with the control connection's peer, as many servers behind NAT
+// report private addresses.
+func parsePASV(msg string, ctrl net.Addr) (string, error) {
+	start := strings.IndexByte(msg, '(')
+	end := strings.IndexByte(msg, ')')
+	if start < 0 || end < start {
+		return "", fmt.Errorf("malformed PASV reply %q", msg)
+	}
+	parts := strings.Split(msg[start+1:end], ",")
+	if len(parts) != 6 {
+		return "", fmt.Errorf("malformed PASV reply %q", msg)
+	}
+	hi, err1 := strconv.Atoi(strings.TrimSpace(parts[4]))
+	lo, err2 := strconv.Atoi(strings.TrimSpace(parts[5]))
+	if err1 != nil || err2 != nil {
+		return "", fmt.Errorf("malformed PASV reply %q", msg)
+	}
+	host, _, err := net.SplitHostPort(ctrl.String())
+	if err != nil {
+		return "", err
+	}
+	return net.JoinHostPort(host, strconv.Itoa(hi*256+lo)), nil
+}
@@ -0,0 +1,118 @@
+package web
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startStubFTP serves a tiny FTP dialogue over loopback. files holds the
+// paths SIZE knows about; dirs the paths CWD accepts. When noSize is set,
+// SIZE is rejected and the checker must fall back to NLST.
+func startStubFTP(t *testing.T, files, dirs []string, noSize bool) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	has := func(list []string, p string) bool {
+		for _, x := range list {
+			if x == p {
+				return true
+			}
+		}
+		return false
+	}
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(c net.Conn) {
+				defer c.Close()
+				r := bufio.NewReader(c)
+				var dataLn net.Listener
+				fmt.Fprintf(c, "220 stub ready\r\n")
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					cmd, arg, _ := strings.Cut(strings.TrimSpace(line), " ")
+					switch strings.ToUpper(cmd) {
+					case "USER":
+						fmt.Fprintf(c, "331 password please\r\n")
+					case "PASS":
+						fmt.Fprintf(c, "230 logged in\r\n")
+					case "CWD":
+						if has(dirs, arg) {
+							fmt.Fprintf(c, "250 ok\r\n")
+						} else {
+							fmt.Fprintf(c, "550 no such directory\r\n")
+						}
+					case "SIZE":
+						switch {
+						case noSize:
+							fmt.Fprintf(c, "502 not implemented\r\n")
+						case has(files, arg):
+							fmt.Fprintf(c, "213 42\r\n")
+						default:
+							fmt.Fprintf(c, "550 no such file\r\n")
+						}
+					case "PASV":
+						dataLn, _ = net.Listen("tcp", "127.0.0.1:0")
+						port := dataLn.Addr().(*net.TCPAddr).Port
+						fmt.Fprintf(c, "227 Entering Passive Mode (10,0,0,1,%d,%d)\r\n", port/256, port%256)
+					case "NLST":
+						fmt.Fprintf(c, "150 here it comes\r\n")
+						dc, err := dataLn.Accept()
+						if err == nil {
+							for _, f := range files {
+								fmt.Fprintf(dc, "%s\r\n", f)
+							}
+							dc.Close()
+						}
+						dataLn.Close()
+						fmt.Fprintf(c, "226 done\r\n")
+					case "QUIT":
+						fmt.Fprintf(c, "221 bye\r\n")
+						return
+					default:
+						fmt.Fprintf(c, "502 unknown\r\n")
+					}
+				}
+			}(c)
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func TestCheckFTPURL(t *testing.T) {
+	addr := startStubFTP(t, []string{"/pub/file.tar.gz"}, []string{"/pub/"}, false)
+	nlst := startStubFTP(t, []string{"/pub/file.tar.gz"}, nil, true)
+
+	cases := []struct {
+		url string
+		ok  bool
+	}{
+		{"ftp://" + addr + "/", true},
+		{"ftp://" + addr + "/pub/", true},
+		{"ftp://" + addr + "/missing/", false},
+		{"ftp://" + addr + "/pub/file.tar.gz", true},
+		{"ftp://user:secret@" + addr + "/pub/missing.tar.gz", false},
+		{"ftp://" + nlst + "/pub/file.tar.gz", true},
+		{"ftp://" + nlst + "/pub/other.tar.gz", false},
+	}
+	for _, c := range cases {
+		ok, status, err := checkFTPURL(context.Background(), c.url, 2*time.Second)
+		if ok != c.ok {
+			t.Fatalf("checkFTPURL(%q) ok=%v status=%d err=%v, want ok=%v", c.url, ok, status, err, c.ok)
+		}
+	}
+}
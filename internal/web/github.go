@@ -0,0 +1,384 @@
+package web
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// GitHubConfig enables checking GitHub links through the REST API instead of
+// scraping pages.
+type GitHubConfig struct {
+	Enabled bool
+	// APIBase is the REST API root (default https://api.github.com); for GitHub
+	// Enterprise Server it is usually https://<host>/api/v3.
+	APIBase string
+	// Hosts are the web hosts whose links are resolved (default github.com).
+	Hosts []string
+	// Token is sent as a bearer token when set.
+	Token string
+}
+
+const defaultGitHubAPIBase = "https://api.github.com"
+
+// errGitHubRateLimited marks API calls refused for an exhausted quota.
+var errGitHubRateLimited = simpleError("GitHub API rate limited")
+
+var githubLineRegex = regexp.MustCompile(`^L(\d+)(?:C\d+)?(?:-L(\d+)(?:C\d+)?)?$`)
+
+// githubSHARegex matches full and abbreviated commit SHAs, which need no ref
+// lookup.
+var githubSHARegex = regexp.MustCompile(`^[0-9a-fA-F]{7,40}$`)
+
+// githubResolver checks links to repositories, files, issues, pull requests,
+// releases and commits with API calls, caching responses for the run.
+type githubResolver struct {
+	client *http.Client
+	cfg    Config
+	base   string
+	hosts  []string
+
+	mu    sync.Mutex
+	cache map[string]*githubCall
+	// limited is set once the API quota runs out; the rest of the run falls
+	// back to plain HTTP checks.
+	limited atomic.Bool
+}
+
+type githubCall struct {
+	once   sync.Once
+	status int
+	header http.Header
+	body   []byte
+	err    error
+}
+
+func newGitHubResolver(client *http.Client, cfg Config) *githubResolver {
+	base := strings.TrimRight(cfg.GitHub.APIBase, "/")
+	if base == "" {
+		base = defaultGitHubAPIBase
+	}
+	hosts := cfg.GitHub.Hosts
+	if len(hosts) == 0 {
+		hosts = []string{"github.com", "www.github.com"}
+	}
+	return &githubResolver{client: client, cfg: cfg, base: base, hosts: hosts, cache: make(map[string]*githubCall)}
+}
+
+func (g *githubResolver) handles(hostname string) bool {
+	for _, h := range g.hosts {
+		if strings.EqualFold(h, hostname) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolve checks raw via the API. handled is false for URLs it does not
+// understand, and for every URL once the API quota is exhausted; those are
+// then checked over plain HTTP.
+func (g *githubResolver) resolve(ctx context.Context, raw string) (res Result, handled bool) {
+	if g.limited.Load() {
+		return Result{}, false
+	}
+	res, handled = g.resolveAPI(ctx, raw)
+	if handled && errors.Is(res.Err, errGitHubRateLimited) {
+		g.limited.Store(true)
+		return Result{}, false
+	}
+	return res, handled
+}
+
+func (g *githubResolver) resolveAPI(ctx context.Context, raw string) (res Result, handled bool) {
+	u, err := url.Parse(raw)
+	if err != nil || !g.handles(u.Hostname()) {
+		return Result{}, false
+	}
+	parts := strings.Split(strings.Trim(u.EscapedPath(), "/"), "/")
+	for i, p := range parts {
+		if s, err := url.PathUnescape(p); err == nil {
+			parts[i] = s
+		}
+	}
+	if len(parts) == 0 || parts[0] == "" {
+		return Result{}, false
+	}
+	if len(parts) == 1 {
+		return g.require(ctx, "/users/"+url.PathEscape(parts[0]), "account", ErrorKindGitHubNotFound), true
+	}
+	owner, repo := parts[0], strings.TrimSuffix(parts[1], ".git")
+	repoPath := "/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(repo)
+	rest := parts[2:]
+	if len(rest) == 0 {
+		return g.require(ctx, repoPath, "repository", ErrorKindGitHubNotFound), true
+	}
+
+	var (
+		apiPath string
+		what    string
+	)
+	switch {
+	case (rest[0] == "issues" || rest[0] == "pull") && len(rest) >= 2 && isNumber(rest[1]):
+		apiPath, what = repoPath+"/issues/"+rest[1], rest[0]+" #"+rest[1]
+		if rest[0] == "pull" {
+			apiPath = repoPath + "/pulls/" + rest[1]
+		}
+	case rest[0] == "releases" && len(rest) == 1:
+		return g.require(ctx, repoPath, "repository", ErrorKindGitHubNotFound), true
+	case rest[0] == "releases" && len(rest) == 2 && rest[1] == "latest":
+		apiPath, what = repoPath+"/releases/latest", "latest release"
+	case rest[0] == "releases" && len(rest) >= 3 && rest[1] == "tag":
+		tag := strings.Join(rest[2:], "/")
+		apiPath, what = repoPath+"/releases/tags/"+url.PathEscape(tag), "release "+tag
+	case (rest[0] == "commit" || rest[0] == "commits") && len(rest) == 2:
+		apiPath, what = repoPath+"/commits/"+url.PathEscape(rest[1]), "commit "+rest[1]
+	case (rest[0] == "blob" || rest[0] == "tree") && len(rest) >= 2:
+		return g.resolveContents(ctx, repoPath, rest[1:], u.Fragment), true
+	default:
+		return Result{}, false
+	}
+	res = g.require(ctx, apiPath, what, ErrorKindGitHubGone)
+	if !res.OK && res.ErrorKind == ErrorKindGitHubGone {
+		g.blameRepo(ctx, repoPath, &res)
+	}
+	return res, true
+}
+
+// resolveContents handles blob/tree links. Refs may contain slashes, so the
+// ref is looked up first and the rest is checked with one contents call.
+func (g *githubResolver) resolveContents(ctx context.Context, repoPath string, refAndPath []string, fragment string) Result {
+	n, res := g.refSegments(ctx, repoPath, refAndPath)
+	if n == 0 {
+		return res
+	}
+	ref := strings.Join(refAndPath[:n], "/")
+	p := strings.Join(refAndPath[n:], "/")
+	call := g.get(ctx, repoPath+"/contents/"+escapeGitHubPath(p)+"?ref="+url.QueryEscape(ref))
+	if call.err != nil {
+		return githubErrorResult(call.err)
+	}
+	res = g.result(call, fmt.Sprintf("%s at %s", displayPath(p), ref), ErrorKindGitHubGone)
+	if res.OK && fragment != "" {
+		checkLineRange(call.body, fragment, &res)
+	}
+	return res
+}
+
+// refSegments returns how many leading segments of refAndPath make up the
+// ref, using the branches, then the tags, that start with its first segment.
+// It returns 0 and the failure when the ref cannot be found.
+func (g *githubResolver) refSegments(ctx context.Context, repoPath string, refAndPath []string) (int, Result) {
+	first := refAndPath[0]
+	if len(refAndPath) == 1 || first == "HEAD" || githubSHARegex.MatchString(first) {
+		return 1, Result{}
+	}
+	for _, kind := range []string{"heads", "tags"} {
+		call := g.get(ctx, repoPath+"/git/matching-refs/"+kind+"/"+url.PathEscape(first))
+		if call.err != nil {
+			return 0, githubErrorResult(call.err)
+		}
+		if call.status != http.StatusOK {
+			return 0, g.result(call, "repository", ErrorKindGitHubNotFound)
+		}
+		var refs []struct {
+			Ref string `json:"ref"`
+		}
+		_ = json.Unmarshal(call.body, &refs)
+		best := 0
+		for _, r := range refs {
+			name := strings.TrimPrefix(r.Ref, "refs/"+kind+"/")
+			n := strings.Count(name, "/") + 1
+			if n > best && n <= len(refAndPath) && strings.Join(refAndPath[:n], "/") == name {
+				best = n
+			}
+		}
+		if best > 0 {
+			return best, Result{}
+		}
+	}
+	return 0, Result{Method: "GITHUB", Status: http.StatusNotFound, ErrorKind: ErrorKindGitHubGone, Err: simpleError("ref " + first + " not found")}
+}
+
+// blameRepo refines a missing sub-resource: if the repository itself cannot
+// be seen, report that instead.
+func (g *githubResolver) blameRepo(ctx context.Context, repoPath string, res *Result) {
+	if repo := g.require(ctx, repoPath, "repository", ErrorKindGitHubNotFound); !repo.OK {
+		*res = repo
+	}
+}
+
+// require reports whether apiPath exists.
+func (g *githubResolver) require(ctx context.Context, apiPath, what string, missing ErrorKind) Result {
+	call := g.get(ctx, apiPath)
+	if call.err != nil {
+		return githubErrorResult(call.err)
+	}
+	return g.result(call, what, missing)
+}
+
+// githubErrorResult reports an API call that got no response.
+func githubErrorResult(err error) Result {
+	return Result{Method: "GITHUB", Err: err, ErrorKind: errorKindOf(err)}
+}
+
+func (g *githubResolver) result(call *githubCall, what string, missing ErrorKind) Result {
+	status := call.status
+	res := Result{Method: "GITHUB", Status: status}
+	switch {
+	case status >= 200 && status < 300:
+		res.OK = true
+	case status == http.StatusTooManyRequests || (status == http.StatusForbidden && call.header.Get("X-RateLimit-Remaining") == "0"):
+		res.Err = fmt.Errorf("%w (%d)", errGitHubRateLimited, status)
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		res.ErrorKind = ErrorKindGitHubNoAccess
+		res.Err = simpleError(fmt.Sprintf("no access to %s (%d)", what, status))
+	case status == http.StatusNotFound || status == http.StatusGone:
+		res.ErrorKind = missing
+		msg := what + " not found"
+		if missing == ErrorKindGitHubNotFound {
+			msg += " or private"
+			if g.cfg.GitHub.Token == "" {
+				msg += " (set GITHUB_TOKEN to check private repositories)"
+			}
+		}
+		res.Err = simpleError(msg)
+	default:
+		res.Err = simpleError(fmt.Sprintf("GitHub API returned %d for %s", status, what))
+	}
+	return res
+}
+
+// get performs (or reuses) an API GET, retrying transient failures like
+// plain HTTP checks do.
+func (g *githubResolver) get(ctx context.Context, apiPath string) *githubCall {
+	g.mu.Lock()
+	call, ok := g.cache[apiPath]
+	if !ok {
+		call = &githubCall{}
+		g.cache[apiPath] = call
+	}
+	g.mu.Unlock()
+	call.once.Do(func() {
+		for attempt := 1; ; attempt++ {
+			fr, err := g.fetch(ctx, g.base+apiPath)
+			call.status, call.header, call.body, call.err = fr.Status, fr.Header, fr.Body, err
+			if attempt > g.cfg.MaxRetries || !retryable(fr, err) || ctx.Err() != nil {
+				return
+			}
+			delay, ok := retryDelay(fr, attempt, g.cfg.RetryBaseDelay, g.cfg.RetryMaxDelay, time.Now())
+			if !ok || !sleepCtx(ctx, delay) {
+				return
+			}
+		}
+	})
+	return call
+}
+
+func (g *githubResolver) fetch(ctx context.Context, apiURL string) (fetchResult, error) {
+	trace := newRequestTrace()
+	req, err := http.NewRequestWithContext(httptrace.WithClientTrace(ctx, trace.clientTrace()), http.MethodGet, apiURL, nil)
+	if err != nil {
+		return fetchResult{}, &kindError{ErrorKindInvalidURL, "invalid URL: " + err.Error(), err}
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	req.Header.Set("User-Agent", "slinky")
+	if g.cfg.GitHub.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+g.cfg.GitHub.Token)
+	}
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return fetchResult{}, classifyError(err, trace)
+	}
+	defer resp.Body.Close()
+	// File contents are base64 in the JSON; allow files up to the API's 1 MB
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 2<<20))
+	if err != nil {
+		return fetchResult{}, classifyError(err, trace)
+	}
+	return fetchResult{Status: resp.StatusCode, Header: resp.Header, Body: body}, nil
+}
+
+// checkLineRange verifies #L10 / #L10-L20 fragments against a contents API
+// file response.
+func checkLineRange(body []byte, fragment string, res *Result) {
+	last, ok := lineRangeEnd(fragment)
+	if !ok {
+		return
+	}
+	var file struct {
+		Type     string `json:"type"`
+		Encoding string `json:"encoding"`
+		Content  string `json:"content"`
+	}
+	if json.Unmarshal(body, &file) != nil || file.Type != "file" || file.Encoding != "base64" {
+		return
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(file.Content, "\n", ""))
+	if err != nil {
+		return
+	}
+	if n := countLines(data); last > n {
+		res.OK = false
+		res.ErrorKind = ErrorKindGitHubGone
+		res.Err = lineRangeError(last, n)
+	}
+}
+
+// lineRangeEnd returns the last line a GitHub #L10 / #L10-L20 fragment
+// refers to.
+func lineRangeEnd(fragment string) (int, bool) {
+	m := githubLineRegex.FindStringSubmatch(fragment)
+	if m == nil {
+		return 0, false
+	}
+	last, _ := strconv.Atoi(m[1])
+	if m[2] != "" {
+		last, _ = strconv.Atoi(m[2])
+	}
+	return last, true
+}
+
+func lineRangeError(last, lines int) error {
+	return simpleError(fmt.Sprintf("line %d is past the end of the file (%d lines)", last, lines))
+}
+
+func countLines(data []byte) int {
+	n := strings.Count(string(data), "\n")
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		n++
+	}
+	return n
+}
+
+func escapeGitHubPath(p string) string {
+	segs := strings.Split(p, "/")
+	for i, s := range segs {
+		segs[i] = url.PathEscape(s)
+	}
+	return strings.Join(segs, "/")
+}
+
+func displayPath(p string) string {
+	if p == "" {
+		return "/"
+	}
+	return p
+}
+
+func isNumber(s string) bool {
+	_, err := strconv.Atoi(s)
+	return err == nil && s != ""
+}
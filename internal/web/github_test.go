@@ -0,0 +1,164 @@
+package web
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGitHubResolver(t *testing.T) {
+	var (
+		gotAuth       string
+		contentsCalls atomic.Int32
+	)
+	content := base64.StdEncoding.EncodeToString([]byte("one\ntwo\nthree\nfour\nfive\n"))
+	mux := http.NewServeMux()
+	ok := func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("{}")) }
+	mux.HandleFunc("GET /users/octo", ok)
+	mux.HandleFunc("GET /repos/octo/hello", func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		w.Write([]byte("{}"))
+	})
+	refs := map[string]string{
+		"heads/main":    `[{"ref": "refs/heads/main"}, {"ref": "refs/heads/maintenance"}]`,
+		"heads/feature": `[{"ref": "refs/heads/feature/x"}, {"ref": "refs/heads/feature/x/y"}]`,
+		"tags/v1.0":     `[{"ref": "refs/tags/v1.0"}]`,
+	}
+	mux.HandleFunc("GET /repos/octo/hello/git/matching-refs/{kind}/{prefix}", func(w http.ResponseWriter, r *http.Request) {
+		body, ok := refs[r.PathValue("kind")+"/"+r.PathValue("prefix")]
+		if !ok {
+			body = "[]"
+		}
+		w.Write([]byte(body))
+	})
+	mux.HandleFunc("GET /repos/octo/hello/contents/README.md", func(w http.ResponseWriter, r *http.Request) {
+		contentsCalls.Add(1)
+		if ref := r.URL.Query().Get("ref"); ref != "main" && ref != "feature/x" && ref != "v1.0" && ref != "0123abcd" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"type": "file", "encoding": "base64", "content": content})
+	})
+	mux.HandleFunc("GET /repos/octo/hello/contents/docs", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("ref") != "main" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("[]"))
+	})
+	mux.HandleFunc("GET /repos/octo/hello/issues/1", ok)
+	mux.HandleFunc("GET /repos/octo/hello/issues/2", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	})
+	mux.HandleFunc("GET /repos/octo/hello/pulls/3", ok)
+	mux.HandleFunc("GET /repos/octo/hello/releases/tags/v1.0", ok)
+	mux.HandleFunc("GET /repos/octo/secret", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	})
+	mux.HandleFunc("/", http.NotFound)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	cfg := Config{GitHub: GitHubConfig{Enabled: true, APIBase: srv.URL, Token: "t0ken"}}
+	g := newGitHubResolver(srv.Client(), cfg)
+	cases := []struct {
+		url  string
+		ok   bool
+		kind ErrorKind
+	}{
+		{"https://github.com/octo", true, ""},
+		{"https://github.com/octo/hello", true, ""},
+		{"https://github.com/octo/hello.git", true, ""},
+		{"https://github.com/octo/hello/blob/main/README.md", true, ""},
+		{"https://github.com/octo/hello/blob/main/README.md#L2-L5", true, ""},
+		{"https://github.com/octo/hello/blob/main/README.md#L4C2-L9", false, ErrorKindGitHubGone},
+		{"https://github.com/octo/hello/blob/feature/x/README.md", true, ""},
+		{"https://github.com/octo/hello/blob/v1.0/README.md", true, ""},
+		{"https://github.com/octo/hello/blob/0123abcd/README.md", true, ""},
+		{"https://github.com/octo/hello/blob/nope/README.md", false, ErrorKindGitHubGone},
+		{"https://github.com/octo/gone/blob/main/README.md", false, ErrorKindGitHubNotFound},
+		{"https://github.com/octo/hello/tree/main/docs", true, ""},
+		{"https://github.com/octo/hello/blob/main/MISSING.md", false, ErrorKindGitHubGone},
+		{"https://github.com/octo/hello/issues/1", true, ""},
+		{"https://github.com/octo/hello/issues/2", false, ErrorKindGitHubGone},
+		{"https://github.com/octo/hello/pull/3", true, ""},
+		{"https://github.com/octo/hello/releases/tag/v1.0", true, ""},
+		{"https://github.com/octo/hello/releases/tag/v9.9", false, ErrorKindGitHubGone},
+		{"https://github.com/octo/gone", false, ErrorKindGitHubNotFound},
+		{"https://github.com/octo/gone/issues/1", false, ErrorKindGitHubNotFound},
+		{"https://github.com/octo/secret", false, ErrorKindGitHubNoAccess},
+	}
+	for _, c := range cases {
+		r, handled := g.resolve(context.Background(), c.url)
+		if !handled {
+			t.Fatalf("%s: not handled", c.url)
+		}
+		if r.OK != c.ok || r.ErrorKind != c.kind || r.Method != "GITHUB" {
+			t.Fatalf("%s: ok=%v kind=%q method=%s err=%v, want ok=%v kind=%q", c.url, r.OK, r.ErrorKind, r.Method, r.Err, c.ok, c.kind)
+		}
+	}
+	// One contents call per ref of README.md, whatever the ref looks like
+	if n := contentsCalls.Load(); n != 4 {
+		t.Fatalf("contents API called %d times, want 4", n)
+	}
+	if gotAuth != "Bearer t0ken" {
+		t.Fatalf("expected bearer token, got %q", gotAuth)
+	}
+
+	for _, u := range []string{"https://github.com/octo/hello/wiki", "https://example.com/octo/hello"} {
+		if _, handled := g.resolve(context.Background(), u); handled {
+			t.Fatalf("%s should fall back to HTTP", u)
+		}
+	}
+}
+
+func TestGitHubResolver_RateLimitFallsBackToHTTP(t *testing.T) {
+	var apiCalls atomic.Int32
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiCalls.Add(1)
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer api.Close()
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer site.Close()
+
+	cfg := Config{MaxRetries: 2, GitHub: GitHubConfig{Enabled: true, APIBase: api.URL, Hosts: []string{"127.0.0.1"}}}
+	h := newHTTPChecker(site.Client(), cfg)
+	for _, p := range []string{"/octo/hello", "/octo/hello/blob/main/deep/missing/path.md", "/octo/other"} {
+		r := h.check(context.Background(), site.URL+p)
+		if !r.OK || r.Method == "GITHUB" {
+			t.Fatalf("%s: ok=%v method=%s err=%v, want a plain HTTP pass", p, r.OK, r.Method, r.Err)
+		}
+	}
+	if n := apiCalls.Load(); n != 1 {
+		t.Fatalf("API called %d times after the quota ran out, want 1", n)
+	}
+}
+
+func TestGitHubResolver_TimeoutIsClassifiedAndRetried(t *testing.T) {
+	var apiCalls atomic.Int32
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiCalls.Add(1)
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer api.Close()
+	client := api.Client()
+	client.Timeout = 50 * time.Millisecond
+
+	cfg := Config{MaxRetries: 1, RetryBaseDelay: time.Millisecond, GitHub: GitHubConfig{Enabled: true, APIBase: api.URL}}
+	r, handled := newGitHubResolver(client, cfg).resolve(context.Background(), "https://github.com/octo/hello")
+	if !handled || r.OK || r.ErrorKind == "" {
+		t.Fatalf("handled=%v ok=%v kind=%q err=%v", handled, r.OK, r.ErrorKind, r.Err)
+	}
+	if n := apiCalls.Load(); n != 2 {
+		t.Fatalf("API called %d times, want 2 with one retry", n)
+	}
+}
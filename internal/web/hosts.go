@@ -0,0 +1,70 @@
+package web
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// HostConfig holds settings for URLs whose hostname matches Match. Match is a
+// glob against the lowercased hostname: "example.com", "*.example.com", or "*"
+// for every host. When several entries match, the first one that sets a given
+// field wins.
+type HostConfig struct {
+	Match string `json:"match"`
+	// Method forces "HEAD" or "GET" as the first request for matching hosts.
+	Method string `json:"method,omitempty"`
+	// MaxConcurrency caps parallel checks per matching host (default 4).
+	MaxConcurrency int `json:"maxConcurrency,omitempty"`
+	// RPS caps requests per second to each matching host.
+	RPS float64 `json:"rps,omitempty"`
+}
+
+// CompileHosts validates host entries and returns normalized copies.
+func CompileHosts(hosts []HostConfig) ([]HostConfig, error) {
+	out := make([]HostConfig, 0, len(hosts))
+	for i, h := range hosts {
+		h.Match = strings.ToLower(strings.TrimSpace(h.Match))
+		if h.Match == "" {
+			return nil, fmt.Errorf("hosts[%d]: match is required", i)
+		}
+		if _, err := path.Match(h.Match, ""); err != nil {
+			return nil, fmt.Errorf("hosts[%d]: %w", i, err)
+		}
+		h.Method = strings.ToUpper(strings.TrimSpace(h.Method))
+		if h.Method != "" && h.Method != "HEAD" && h.Method != "GET" {
+			return nil, fmt.Errorf("hosts[%d]: method must be HEAD or GET, got %q", i, h.Method)
+		}
+		if h.MaxConcurrency < 0 || h.RPS < 0 {
+			return nil, fmt.Errorf("hosts[%d]: maxConcurrency and rps must not be negative", i)
+		}
+		out = append(out, h)
+	}
+	return out, nil
+}
+
+// matchHost reports whether hostname matches a host glob.
+func matchHost(pattern, hostname string) bool {
+	ok, _ := path.Match(pattern, strings.ToLower(hostname))
+	return ok
+}
+
+// hostConfigs returns the entries that apply to hostname, in config order.
+func hostConfigs(hosts []HostConfig, hostname string) []HostConfig {
+	var out []HostConfig
+	for _, h := range hosts {
+		if matchHost(h.Match, hostname) {
+			out = append(out, h)
+		}
+	}
+	return out
+}
+
+func hostnameOf(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
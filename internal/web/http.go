@@ -2,65 +2,284 @@ package web
 
 import (
 	"context"
-	"errors"
-	"net"
+	"fmt"
+	"io"
 	"net/http"
-	"strings"
+	"net/http/httptrace"
+	"time"
 )
 
 const browserUA = "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/123.0 Safari/537.36"
 
-func fetchWithMethod(ctx context.Context, client *http.Client, method string, raw string) (bool, int, *http.Response, error) {
-	req, err := http.NewRequestWithContext(ctx, method, raw, nil)
-	if err != nil {
-		return false, 0, nil, err
+// slowWarningPrefix starts the warning for a response over the slow threshold.
+const slowWarningPrefix = "slow response: "
+
+// defaultMaxBodyBytes bounds how much of a GET response body is read.
+const defaultMaxBodyBytes = 64 << 10
+
+// getOnlyHosts answer HEAD with errors or misleading statuses, so they are
+// checked with GET unless a host entry says otherwise.
+var getOnlyHosts = []string{
+	"linkedin.com", "*.linkedin.com",
+	"twitter.com", "x.com",
+	"medium.com", "*.medium.com",
+	"amazon.com", "*.amazon.com",
+	"npmjs.com", "www.npmjs.com",
+}
+
+// fetchResult is what a single request yields. The body is already closed;
+// Body holds at most the configured number of leading bytes (nothing for HEAD).
+type fetchResult struct {
+	Status    int
+	Header    http.Header
+	Body      []byte
+	Redirects []RedirectHop
+	Timing    Timing
+}
+
+// httpChecker checks http(s) URLs for one CheckURLs run.
+type httpChecker struct {
+	client  *http.Client
+	cfg     Config
+	soft404 *soft404Detector
+	github  *githubResolver
+	self    *selfRepoResolver
+}
+
+func newHTTPChecker(client *http.Client, cfg Config) *httpChecker {
+	// Redirects are followed by fetch so every hop can be recorded
+	c := *client
+	c.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	h := &httpChecker{client: &c, cfg: cfg}
+	if cfg.Soft404.Enabled {
+		h.soft404 = newSoft404Detector(cfg.Soft404)
 	}
-	req.Header.Set("User-Agent", browserUA)
-	req.Header.Set("Accept", "*/*")
-	resp, err := client.Do(req)
-	if err != nil {
-		if isDNSError(err) {
-			return false, 404, nil, simpleError("host not found")
+	if cfg.SelfRepo.Owner != "" && cfg.SelfRepo.Repo != "" {
+		h.self = newSelfRepoResolver(cfg.SelfRepo)
+	}
+	if cfg.GitHub.Enabled {
+		h.github = newGitHubResolver(client, cfg)
+	}
+	return h
+}
+
+// checkHTTP checks a single URL with a fresh httpChecker.
+func checkHTTP(ctx context.Context, client *http.Client, cfg Config, raw string) Result {
+	return newHTTPChecker(client, cfg).check(ctx, raw)
+}
+
+// check tries HEAD first unless the host is configured or known to need GET,
+// and retries with GET when HEAD is refused. Transient failures are retried
+// up to cfg.MaxRetries times.
+func (h *httpChecker) check(ctx context.Context, raw string) Result {
+	if h.self != nil {
+		if res, ok := h.self.resolve(raw); ok {
+			return res
+		}
+	}
+	if h.github != nil {
+		if res, ok := h.github.resolve(ctx, raw); ok {
+			return res
+		}
+	}
+	client, cfg := h.client, h.cfg
+	pointer, hasPointer := pointerFragment(raw)
+	method := firstMethod(cfg.Hosts, hostnameOf(raw), h.soft404 != nil || hasPointer)
+	maxBody := cfg.MaxBodyBytes
+	if hasPointer {
+		maxBody = max(maxBody, maxPointerDocBytes)
+	}
+	var (
+		fr       fetchResult
+		err      error
+		attempts int
+	)
+	for {
+		attempts++
+		fr, err = fetch(ctx, client, method, raw, maxBody, cfg.MaxRedirects)
+		if method == http.MethodHead && err == nil && headUnsupported(fr.Status) && !retryable(fr, nil) {
+			method = http.MethodGet
+			fr, err = fetch(ctx, client, method, raw, maxBody, cfg.MaxRedirects)
+		}
+		if attempts > cfg.MaxRetries || !retryable(fr, err) || ctx.Err() != nil {
+			break
+		}
+		delay, ok := retryDelay(fr, attempts, cfg.RetryBaseDelay, cfg.RetryMaxDelay, time.Now())
+		if !ok || !sleepCtx(ctx, delay) {
+			break
+		}
+	}
+	res := Result{Status: fr.Status, Err: err, Method: method, ContentType: fr.Header.Get("Content-Type"), Attempts: attempts, Redirects: fr.Redirects}
+	res.etag, res.lastModified = fr.Header.Get("ETag"), fr.Header.Get("Last-Modified")
+	timing := fr.Timing
+	res.Timing = &timing
+	res.ErrorKind = errorKindOf(err)
+	if err == nil && fr.Status == http.StatusNotModified && len(fr.Redirects) == 0 {
+		if _, sent := validatorsFor(ctx, raw); sent {
+			res.notModified = true
+			return res
+		}
+	}
+	if err == nil {
+		res.OK, res.StatusRule = evaluateStatus(cfg.StatusPolicies, raw, fr.Status)
+		if !res.OK {
+			res.ErrorKind = ErrorKindHTTPStatus
+		}
+	}
+	if res.OK {
+		res.Warnings, res.SuggestedURL = redirectFindings(raw, fr.Redirects)
+		if insecureTLS(cfg.TLS.Hosts, raw, fr.Redirects) {
+			res.Warnings = append(res.Warnings, "TLS certificate not verified (insecureSkipVerify)")
+		}
+	}
+	if res.OK && hasPointer && method == http.MethodGet && fr.Status < 300 && isPointerDocument(res.ContentType, finalURL(raw, fr.Redirects)) {
+		warning, err := resolvePointer(fr, pointer)
+		switch {
+		case err != nil:
+			res.OK = false
+			res.Err = err
+			res.ErrorKind = ErrorKindJSONPointer
+		case warning != "":
+			res.Warnings = append(res.Warnings, warning)
+		}
+	}
+	if res.OK && h.soft404 != nil && fr.Status < 300 {
+		if reason := h.soft404.detect(ctx, client, raw, fr); reason != "" {
+			res.ErrorKind = ErrorKindSoft404
+			if cfg.Soft404.Warn {
+				res.Warnings = append(res.Warnings, "soft 404: "+reason)
+			} else {
+				res.OK = false
+				res.Err = simpleError("soft 404: " + reason)
+			}
 		}
-		if isTimeout(err) {
-			return false, 408, nil, simpleError("request timeout")
+	}
+	if res.OK && cfg.HTTPSUpgrade && res.SuggestedURL == "" && res.ErrorKind == "" {
+		if warning, suggested := h.httpsUpgrade(ctx, raw, method, fr); suggested != "" {
+			res.Warnings = append(res.Warnings, warning)
+			res.SuggestedURL = suggested
+			res.ErrorKind = ErrorKindHTTPSUpgrade
 		}
-		if isRefused(err) {
-			return false, 503, nil, simpleError("connection refused")
+	}
+	// Last, so the other findings don't depend on this run's speed
+	if res.OK && cfg.SlowThreshold > 0 && timing.Total > cfg.SlowThreshold {
+		res.Warnings = append(res.Warnings, fmt.Sprintf("%s%s (threshold %s)", slowWarningPrefix, timing.Total.Round(time.Millisecond), cfg.SlowThreshold))
+		if res.ErrorKind == "" {
+			res.ErrorKind = ErrorKindSlow
 		}
-		return false, 0, nil, err
 	}
-	return resp.StatusCode >= 200 && resp.StatusCode < 400, resp.StatusCode, resp, nil
+	return res
 }
 
-func errString(e error) string {
-	if e == nil {
-		return ""
+// firstMethod picks the method for the first request to hostname. needBody
+// makes GET the default, for checks that inspect the page.
+func firstMethod(hosts []HostConfig, hostname string, needBody bool) string {
+	for _, h := range hostConfigs(hosts, hostname) {
+		if h.Method != "" {
+			return h.Method
+		}
 	}
-	return e.Error()
+	if needBody {
+		return http.MethodGet
+	}
+	for _, p := range getOnlyHosts {
+		if matchHost(p, hostname) {
+			return http.MethodGet
+		}
+	}
+	return http.MethodHead
 }
 
-func isTimeout(err error) bool {
-	if err == nil {
-		return false
-	}
-	if errors.Is(err, context.DeadlineExceeded) {
-		return true
+// headUnsupported reports statuses that servers commonly return for HEAD even
+// though GET would succeed.
+func headUnsupported(status int) bool {
+	return status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented || status == http.StatusForbidden
+}
+
+// fetch requests raw and follows up to maxRedirects redirects itself (the
+// client must not follow them), recording each hop.
+func fetch(ctx context.Context, client *http.Client, method string, raw string, maxBody int64, maxRedirects int) (fetchResult, error) {
+	if maxRedirects <= 0 {
+		maxRedirects = defaultMaxRedirects
 	}
-	if ne, ok := err.(net.Error); ok && ne.Timeout() {
-		return true
+	seen := map[string]struct{}{raw: {}}
+	var (
+		hops   []RedirectHop
+		timing Timing
+	)
+	current := raw
+	for {
+		fr, loc, err := fetchOnce(ctx, client, method, current, maxBody)
+		timing = timing.add(fr.Timing)
+		fr.Timing = timing
+		if err != nil || loc == "" {
+			fr.Redirects = hops
+			return fr, err
+		}
+		hops = append(hops, RedirectHop{URL: current, Status: fr.Status, Location: loc})
+		fr.Redirects = hops
+		if _, ok := seen[loc]; ok {
+			return fr, errRedirectLoop
+		}
+		if len(hops) > maxRedirects {
+			return fr, errTooManyRedirects
+		}
+		seen[loc] = struct{}{}
+		current = loc
 	}
-	return false
 }
 
-func isDNSError(err error) bool {
-	msg := strings.ToLower(err.Error())
-	return strings.Contains(msg, "no such host") || strings.Contains(msg, "server misbehaving")
+// fetchOnce performs a single request. For redirect responses it returns the
+// resolved Location instead of reading the body.
+func fetchOnce(ctx context.Context, client *http.Client, method string, raw string, maxBody int64) (fetchResult, string, error) {
+	trace := newRequestTrace()
+	req, err := http.NewRequestWithContext(httptrace.WithClientTrace(ctx, trace.clientTrace()), method, raw, nil)
+	if err != nil {
+		return fetchResult{}, "", &kindError{ErrorKindInvalidURL, "invalid URL: " + err.Error(), err}
+	}
+	req.Header.Set("User-Agent", browserUA)
+	req.Header.Set("Accept", "*/*")
+	if v, ok := validatorsFor(ctx, raw); ok {
+		if v.etag != "" {
+			req.Header.Set("If-None-Match", v.etag)
+		}
+		if v.lastModified != "" {
+			req.Header.Set("If-Modified-Since", v.lastModified)
+		}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		// No response, so no status: the kind says what went wrong
+		return fetchResult{Timing: trace.timing()}, "", classifyError(err, trace)
+	}
+	defer resp.Body.Close()
+	fr := fetchResult{
+		Status: resp.StatusCode,
+		Header: resp.Header,
+	}
+	if isRedirectStatus(resp.StatusCode) {
+		if loc, err := resp.Location(); err == nil {
+			fr.Timing = trace.timing()
+			return fr, loc.String(), nil
+		}
+	}
+	if method != http.MethodHead {
+		if maxBody <= 0 {
+			maxBody = defaultMaxBodyBytes
+		}
+		// Never download more than the prefix; closing early drops the connection
+		// for huge assets, which is cheaper than draining them.
+		fr.Body, _ = io.ReadAll(io.LimitReader(resp.Body, maxBody))
+	}
+	fr.Timing = trace.timing()
+	return fr, "", nil
 }
 
-func isRefused(err error) bool {
-	msg := strings.ToLower(err.Error())
-	return strings.Contains(msg, "connection refused")
+func errString(e error) string {
+	if e == nil {
+		return ""
+	}
+	return e.Error()
 }
 
 type simpleError string
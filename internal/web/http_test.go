@@ -0,0 +1,84 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckHTTP_HeadFallback(t *testing.T) {
+	var methods []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method+" "+r.URL.Path)
+		switch r.URL.Path {
+		case "/no-head":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+	client := srv.Client()
+	client.Timeout = 2 * time.Second
+
+	cases := []struct {
+		path   string
+		cfg    Config
+		ok     bool
+		method string
+		reqs   []string
+	}{
+		{"/plain", Config{}, true, "HEAD", []string{"HEAD /plain"}},
+		{"/no-head", Config{}, true, "GET", []string{"HEAD /no-head", "GET /no-head"}},
+		{"/missing", Config{}, false, "HEAD", []string{"HEAD /missing"}},
+		{"/plain", Config{Hosts: []HostConfig{{Match: "127.0.0.1", Method: "GET"}}}, true, "GET", []string{"GET /plain"}},
+		{"/plain", Config{Hosts: []HostConfig{{Match: "*.example.com", Method: "GET"}}}, true, "HEAD", []string{"HEAD /plain"}},
+	}
+	for _, c := range cases {
+		methods = nil
+		r := checkHTTP(context.Background(), client, c.cfg, srv.URL+c.path)
+		if r.OK != c.ok || r.Method != c.method {
+			t.Fatalf("%s: ok=%v method=%s err=%v, want ok=%v method=%s", c.path, r.OK, r.Method, r.Err, c.ok, c.method)
+		}
+		if strings.Join(methods, ",") != strings.Join(c.reqs, ",") {
+			t.Fatalf("%s: requests %v, want %v", c.path, methods, c.reqs)
+		}
+		if c.ok && r.ContentType != "text/plain" {
+			t.Fatalf("%s: content type %q", c.path, r.ContentType)
+		}
+	}
+}
+
+func TestFetch_BoundedBody(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("x", 1<<20)))
+	}))
+	defer srv.Close()
+	fr, err := fetch(context.Background(), srv.Client(), http.MethodGet, srv.URL, 1024, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fr.Body) != 1024 {
+		t.Fatalf("expected 1024 body bytes, got %d", len(fr.Body))
+	}
+}
+
+func TestFirstMethod_KnownHosts(t *testing.T) {
+	u, _ := url.Parse("https://www.linkedin.com/in/someone")
+	if m := firstMethod(nil, u.Hostname(), false); m != http.MethodGet {
+		t.Fatalf("expected GET for linkedin, got %s", m)
+	}
+	if m := firstMethod([]HostConfig{{Match: "*linkedin.com", Method: "HEAD"}}, u.Hostname(), false); m != http.MethodHead {
+		t.Fatalf("expected configured HEAD to win, got %s", m)
+	}
+}
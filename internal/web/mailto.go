@@ -0,0 +1,179 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// RFC 6068 hfield names are RFC 5322 field names: printable ASCII except ':'
+var hfieldNameRegex = regexp.MustCompile(`^[!-9;-~]+$`)
+
+func isMailtoURL(raw string) bool {
+	return strings.HasPrefix(strings.ToLower(raw), "mailto:")
+}
+
+// mailtoAddresses parses a mailto: URL per RFC 6068 and returns every
+// recipient it names (the to-part plus to/cc/bcc hfields).
+func mailtoAddresses(raw string) ([]string, error) {
+	rest := raw[len("mailto:"):]
+	toPart, query, hasQuery := strings.Cut(rest, "?")
+	var addrs []string
+	addList := func(list string) error {
+		for a := range strings.SplitSeq(list, ",") {
+			dec, err := url.PathUnescape(a)
+			if err != nil {
+				return fmt.Errorf("invalid percent-encoding in %q", a)
+			}
+			if dec = strings.TrimSpace(dec); dec != "" {
+				addrs = append(addrs, dec)
+			}
+		}
+		return nil
+	}
+	if err := addList(toPart); err != nil {
+		return nil, err
+	}
+	if hasQuery {
+		for field := range strings.SplitSeq(query, "&") {
+			if field == "" {
+				continue
+			}
+			name, value, ok := strings.Cut(field, "=")
+			if !ok {
+				return nil, fmt.Errorf("malformed header field %q", field)
+			}
+			hname, err := url.QueryUnescape(name)
+			if err != nil || !hfieldNameRegex.MatchString(hname) {
+				return nil, fmt.Errorf("invalid header name %q", name)
+			}
+			if _, err := url.QueryUnescape(value); err != nil {
+				return nil, fmt.Errorf("invalid percent-encoding in %q", value)
+			}
+			switch strings.ToLower(hname) {
+			case "to", "cc", "bcc":
+				if err := addList(value); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	if len(addrs) == 0 {
+		return nil, simpleError("no recipient address")
+	}
+	return addrs, nil
+}
+
+// validateAddrSpec checks a single address against RFC 5322 addr-spec; display
+// names and group syntax are not allowed in mailto: URLs.
+func validateAddrSpec(addr string) (domain string, err error) {
+	parsed, err := mail.ParseAddress(addr)
+	if err != nil || parsed.Name != "" || parsed.Address != addr {
+		return "", fmt.Errorf("invalid address %q", addr)
+	}
+	at := strings.LastIndexByte(addr, '@')
+	domain = addr[at+1:]
+	if strings.HasPrefix(domain, "[") {
+		// Domain literals ([192.0.2.1]) have nothing to look up
+		return "", nil
+	}
+	return strings.ToLower(domain), nil
+}
+
+// mailDomainChecker verifies that a domain can receive mail (MX, or A/AAAA as
+// the RFC 5321 implicit MX), caching answers per domain for the run.
+type mailDomainChecker struct {
+	resolver *net.Resolver
+	mu       sync.Mutex
+	cache    map[string]error
+}
+
+func newMailDomainChecker(server string) *mailDomainChecker {
+	r := net.DefaultResolver
+	if server != "" {
+		r = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, server)
+			},
+		}
+	}
+	return &mailDomainChecker{resolver: r, cache: make(map[string]error)}
+}
+
+func (c *mailDomainChecker) check(ctx context.Context, domain string) error {
+	c.mu.Lock()
+	err, ok := c.cache[domain]
+	c.mu.Unlock()
+	if ok {
+		return err
+	}
+	err = c.lookup(ctx, domain)
+	// Resolver failures carry a kind and say nothing about the domain, so the
+	// next link to it asks again; answers are cached
+	if errorKindOf(err) == "" {
+		c.mu.Lock()
+		c.cache[domain] = err
+		c.mu.Unlock()
+	}
+	return err
+}
+
+// lookup reports whether domain can receive mail. When the resolver fails
+// (timeout, SERVFAIL) rather than answering, the error is classified as dns.
+func (c *mailDomainChecker) lookup(ctx context.Context, domain string) error {
+	mxs, err := c.resolver.LookupMX(ctx, domain)
+	if err == nil && len(mxs) > 0 {
+		// RFC 7505 null MX: the domain explicitly accepts no mail
+		if len(mxs) == 1 && (mxs[0].Host == "." || mxs[0].Host == "") {
+			return fmt.Errorf("domain %s does not accept mail (null MX)", domain)
+		}
+		return nil
+	}
+	if err != nil && !dnsNotFound(err) {
+		return classifyError(err, nil)
+	}
+	hosts, err := c.resolver.LookupHost(ctx, domain)
+	if err == nil && len(hosts) > 0 {
+		return nil
+	}
+	if err != nil && !dnsNotFound(err) {
+		return classifyError(err, nil)
+	}
+	return fmt.Errorf("no MX or A records for %s", domain)
+}
+
+// dnsNotFound reports whether err is an authoritative "no such name or
+// record" answer.
+func dnsNotFound(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
+
+// checkMailto validates a mailto: URL's syntax and, when domains is non-nil,
+// that every recipient domain can receive mail.
+func checkMailto(ctx context.Context, raw string, domains *mailDomainChecker) (bool, error) {
+	addrs, err := mailtoAddresses(raw)
+	if err != nil {
+		return false, err
+	}
+	for _, a := range addrs {
+		domain, err := validateAddrSpec(a)
+		if err != nil {
+			return false, err
+		}
+		if domains != nil && domain != "" {
+			if err := domains.check(ctx, domain); err != nil {
+				return false, err
+			}
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,172 @@
+package web
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestCheckMailto_Syntax(t *testing.T) {
+	cases := map[string]bool{
+		"mailto:team@example.com":                      true,
+		"mailto:a@example.com,b@example.org":           true,
+		"mailto:?to=a@example.com&subject=Hi%20there":  true,
+		"mailto:a@example.com?cc=b@example.com&body=x": true,
+		"mailto:first%2Elast@example.com":              true,
+		"mailto:not-an-address":                        false,
+		"mailto:John%20Doe%20%3Cjd@example.com%3E":     false,
+		"mailto:a@example.com?subject":                 false,
+		"mailto:?subject=no-recipient":                 false,
+		"mailto:a@example.com?cc=bad@@example.com":     false,
+	}
+	for raw, want := range cases {
+		ok, err := checkMailto(context.Background(), raw, nil)
+		if ok != want {
+			t.Fatalf("checkMailto(%q) = %v (%v), want %v", raw, ok, err, want)
+		}
+	}
+}
+
+func TestCheckMailto_DNS(t *testing.T) {
+	server := startStubDNS(t, map[string]stubRecord{
+		"mx.example.":     {mx: "mail.mx.example."},
+		"aonly.example.":  {a: net.IPv4(192, 0, 2, 1)},
+		"nullmx.example.": {mx: "."},
+	})
+	domains := newMailDomainChecker(server)
+
+	cases := map[string]bool{
+		"mailto:a@mx.example":      true,
+		"mailto:a@aonly.example":   true,
+		"mailto:a@nullmx.example":  false,
+		"mailto:a@missing.example": false,
+	}
+	for raw, want := range cases {
+		ok, err := checkMailto(context.Background(), raw, domains)
+		if ok != want {
+			t.Fatalf("checkMailto(%q) = %v (%v), want %v", raw, ok, err, want)
+		}
+	}
+}
+
+func TestCheckMailto_DNSFailureNotCached(t *testing.T) {
+	server := startStubDNS(t, map[string]stubRecord{
+		"broken.example.": {servfail: true},
+	})
+	domains := newMailDomainChecker(server)
+
+	ok, err := checkMailto(context.Background(), "mailto:a@broken.example", domains)
+	if ok || errorKindOf(err) != ErrorKindDNS || strings.Contains(err.Error(), "no MX or A records") {
+		t.Fatalf("got ok=%v err=%v (kind %q), want a dns failure", ok, err, errorKindOf(err))
+	}
+	if _, cached := domains.cache["broken.example"]; cached {
+		t.Fatalf("a failed lookup was cached as the domain's answer")
+	}
+
+	ok, err = checkMailto(context.Background(), "mailto:a@missing.example", domains)
+	if ok || errorKindOf(err) != "" || !strings.Contains(err.Error(), "no MX or A records") {
+		t.Fatalf("got ok=%v err=%v, want no records for an NXDOMAIN answer", ok, err)
+	}
+	if _, cached := domains.cache["missing.example"]; !cached {
+		t.Fatalf("an NXDOMAIN answer was not cached")
+	}
+}
+
+type stubRecord struct {
+	mx       string
+	a        net.IP
+	servfail bool
+}
+
+// startStubDNS serves MX and A answers from records over UDP on localhost and
+// returns its address. Unknown names get NXDOMAIN.
+func startStubDNS(t *testing.T, records map[string]stubRecord) string {
+	t.Helper()
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { pc.Close() })
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := pc.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if resp := stubDNSAnswer(buf[:n], records); resp != nil {
+				_, _ = pc.WriteTo(resp, addr)
+			}
+		}
+	}()
+	return pc.LocalAddr().String()
+}
+
+func stubDNSAnswer(q []byte, records map[string]stubRecord) []byte {
+	if len(q) < 12 {
+		return nil
+	}
+	// Question name starts at offset 12
+	var labels []string
+	i := 12
+	for i < len(q) && q[i] != 0 {
+		l := int(q[i])
+		labels = append(labels, string(q[i+1:i+1+l]))
+		i += l + 1
+	}
+	qEnd := i + 5 // zero byte + QTYPE + QCLASS
+	if qEnd > len(q) {
+		return nil
+	}
+	qtype := binary.BigEndian.Uint16(q[i+1 : i+3])
+	name := strings.ToLower(strings.Join(labels, ".")) + "."
+
+	rec, known := records[name]
+	var answers [][]byte
+	rr := func(typ uint16, rdata []byte) []byte {
+		b := []byte{0xC0, 0x0C} // pointer to question name
+		b = binary.BigEndian.AppendUint16(b, typ)
+		b = binary.BigEndian.AppendUint16(b, 1)
+		b = binary.BigEndian.AppendUint32(b, 60)
+		b = binary.BigEndian.AppendUint16(b, uint16(len(rdata)))
+		return append(b, rdata...)
+	}
+	switch {
+	case qtype == 15 && rec.mx != "":
+		rdata := []byte{0, 10}
+		if rec.mx == "." {
+			rdata = []byte{0, 0, 0}
+		} else {
+			for _, l := range strings.Split(strings.TrimSuffix(rec.mx, "."), ".") {
+				rdata = append(rdata, byte(len(l)))
+				rdata = append(rdata, l...)
+			}
+			rdata = append(rdata, 0)
+		}
+		answers = append(answers, rr(15, rdata))
+	case qtype == 1 && rec.a != nil:
+		answers = append(answers, rr(1, rec.a.To4()))
+	}
+
+	resp := append([]byte(nil), q[:2]...)
+	flags := uint16(0x8180) // response, recursion desired+available
+	switch {
+	case !known:
+		flags |= 3 // NXDOMAIN
+	case rec.servfail:
+		flags |= 2 // SERVFAIL
+		answers = nil
+	}
+	resp = binary.BigEndian.AppendUint16(resp, flags)
+	resp = binary.BigEndian.AppendUint16(resp, 1)
+	resp = binary.BigEndian.AppendUint16(resp, uint16(len(answers)))
+	resp = binary.BigEndian.AppendUint16(resp, 0)
+	resp = binary.BigEndian.AppendUint16(resp, 0)
+	resp = append(resp, q[12:qEnd]...)
+	for _, a := range answers {
+		resp = append(resp, a...)
+	}
+	return resp
+}
@@ -0,0 +1,60 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// defaultMaxRedirects bounds redirect chains unless Config.MaxRedirects is set.
+const defaultMaxRedirects = 10
+
+var (
+	errRedirectLoop     = &kindError{kind: ErrorKindRedirectLoop, msg: "redirect loop"}
+	errTooManyRedirects = &kindError{kind: ErrorKindTooManyRedirects, msg: "too many redirects"}
+)
+
+// RedirectHop is one response in a redirect chain: the URL requested, the
+// redirect status it answered with, and the resolved Location.
+type RedirectHop struct {
+	URL      string `json:"url"`
+	Status   int    `json:"status"`
+	Location string `json:"location"`
+}
+
+func isRedirectStatus(status int) bool {
+	switch status {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
+
+func isPermanentRedirect(status int) bool {
+	return status == http.StatusMovedPermanently || status == http.StatusPermanentRedirect
+}
+
+// redirectFindings turns a chain into warnings and, when it starts with
+// permanent redirects, the URL links should point at instead: the target of
+// the last hop in that leading run of 301/308s. The fragment of raw is kept,
+// since servers never see it.
+func redirectFindings(raw string, hops []RedirectHop) (warnings []string, suggested string) {
+	for _, h := range hops {
+		if !isPermanentRedirect(h.Status) {
+			break
+		}
+		suggested = h.Location
+	}
+	if _, frag, ok := strings.Cut(raw, "#"); ok && suggested != "" && !strings.Contains(suggested, "#") {
+		suggested += "#" + frag
+	}
+	if suggested != "" {
+		warnings = append(warnings, fmt.Sprintf("permanent redirect; update link to %s", suggested))
+	}
+	for _, h := range hops {
+		if strings.HasPrefix(strings.ToLower(h.URL), "https:") && strings.HasPrefix(strings.ToLower(h.Location), "http:") {
+			warnings = append(warnings, fmt.Sprintf("redirect downgrades HTTPS to HTTP: %s -> %s", h.URL, h.Location))
+		}
+	}
+	return warnings, suggested
+}
@@ -0,0 +1,80 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCheckHTTP_Redirects(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/newer", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/newer", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/new", http.StatusPermanentRedirect)
+	})
+	mux.HandleFunc("/new", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/temp", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/new", http.StatusFound)
+	})
+	mux.HandleFunc("/loop-a", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop-b", http.StatusFound)
+	})
+	mux.HandleFunc("/loop-b", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop-a", http.StatusFound)
+	})
+	mux.HandleFunc("/chain/", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, r.URL.Path+"x", http.StatusFound)
+	})
+	mux.HandleFunc("/gone", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/missing", http.StatusMovedPermanently)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	cfg := Config{MaxRedirects: 5}
+	r := checkHTTP(context.Background(), srv.Client(), cfg, srv.URL+"/old")
+	if !r.OK || len(r.Redirects) != 2 || r.SuggestedURL != srv.URL+"/new" || len(r.Warnings) != 1 {
+		t.Fatalf("/old: ok=%v redirects=%+v suggested=%q warnings=%v", r.OK, r.Redirects, r.SuggestedURL, r.Warnings)
+	}
+	if h := r.Redirects[0]; h.URL != srv.URL+"/old" || h.Status != 301 || h.Location != srv.URL+"/newer" {
+		t.Fatalf("unexpected first hop %+v", h)
+	}
+
+	r = checkHTTP(context.Background(), srv.Client(), cfg, srv.URL+"/old#section")
+	if r.SuggestedURL != srv.URL+"/new#section" {
+		t.Fatalf("/old#section: suggested=%q", r.SuggestedURL)
+	}
+
+	r = checkHTTP(context.Background(), srv.Client(), cfg, srv.URL+"/temp")
+	if !r.OK || len(r.Redirects) != 1 || r.SuggestedURL != "" || len(r.Warnings) != 0 {
+		t.Fatalf("/temp: ok=%v suggested=%q warnings=%v", r.OK, r.SuggestedURL, r.Warnings)
+	}
+
+	r = checkHTTP(context.Background(), srv.Client(), cfg, srv.URL+"/loop-a")
+	if r.OK || r.Err != errRedirectLoop {
+		t.Fatalf("/loop-a: ok=%v err=%v", r.OK, r.Err)
+	}
+
+	r = checkHTTP(context.Background(), srv.Client(), cfg, srv.URL+"/chain/")
+	if r.OK || r.Err != errTooManyRedirects || len(r.Redirects) != 6 {
+		t.Fatalf("/chain/: ok=%v err=%v hops=%d", r.OK, r.Err, len(r.Redirects))
+	}
+
+	r = checkHTTP(context.Background(), srv.Client(), cfg, srv.URL+"/gone")
+	if r.OK || r.Status != 404 || r.SuggestedURL != "" {
+		t.Fatalf("/gone: ok=%v status=%d suggested=%q", r.OK, r.Status, r.SuggestedURL)
+	}
+}
+
+func TestRedirectFindings_Downgrade(t *testing.T) {
+	warnings, suggested := redirectFindings("https://example.com/a", []RedirectHop{
+		{URL: "https://example.com/a", Status: 302, Location: "http://example.com/b"},
+	})
+	if suggested != "" || len(warnings) != 1 || !strings.Contains(warnings[0], "downgrades HTTPS to HTTP") {
+		t.Fatalf("warnings=%v suggested=%q", warnings, suggested)
+	}
+}
@@ -0,0 +1,64 @@
+package web
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"slinky/internal/jsonpointer"
+)
+
+// maxPointerDocBytes bounds the body read for a URL with a JSON pointer
+// fragment: the pointer can only be resolved in the whole document.
+const maxPointerDocBytes = 8 << 20
+
+// pointerFragment returns the JSON pointer in raw's fragment, as in remote
+// OpenAPI $refs ("https://example.com/schemas.yaml#/Pet").
+func pointerFragment(raw string) (string, bool) {
+	u, err := url.Parse(raw)
+	if err != nil || !strings.HasPrefix(u.Fragment, "/") {
+		return "", false
+	}
+	return u.Fragment, true
+}
+
+// isPointerDocument reports whether a response is a JSON or YAML document, by
+// its Content-Type or, for the text/plain of raw file hosts, by the extension
+// of finalURL. Single-page apps use "#/route" fragments on HTML pages.
+func isPointerDocument(contentType, finalURL string) bool {
+	mt := mediaType(contentType)
+	switch {
+	case mt == "application/json", mt == "text/json", strings.HasSuffix(mt, "+json"),
+		mt == "application/yaml", mt == "application/x-yaml", mt == "text/yaml", mt == "text/x-yaml":
+		return true
+	case mt == "text/plain", mt == "application/octet-stream", mt == "":
+		u, err := url.Parse(finalURL)
+		if err != nil {
+			return false
+		}
+		switch strings.ToLower(path.Ext(u.Path)) {
+		case ".json", ".yaml", ".yml":
+			return true
+		}
+	}
+	return false
+}
+
+// resolvePointer parses the document in fr and resolves pointer in it. The
+// warning is set instead when the document was too large to read whole.
+func resolvePointer(fr fetchResult, pointer string) (warning string, err error) {
+	if int64(len(fr.Body)) >= maxPointerDocBytes {
+		return fmt.Sprintf("document larger than %d MiB, JSON pointer %s not checked", maxPointerDocBytes>>20, pointer), nil
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(fr.Body, &doc); err != nil {
+		return "", fmt.Errorf("parse document for JSON pointer %s: %w", pointer, err)
+	}
+	if _, err := jsonpointer.Resolve(&doc, pointer); err != nil {
+		return "", err
+	}
+	return "", nil
+}
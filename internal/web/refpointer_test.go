@@ -0,0 +1,52 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCheckHTTP_RemoteRefPointer(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/schemas.yaml":
+			w.Header().Set("Content-Type", "application/yaml")
+			w.Write([]byte("Pet:\n  type: object\n  properties:\n    name: {type: string}\n"))
+		case "/raw/errors.json":
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			w.Write([]byte(`{"Error": {"type": "object"}}`))
+		case "/app":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("<html><body>app</body></html>"))
+		}
+	}))
+	defer srv.Close()
+	client := srv.Client()
+	client.Timeout = 2 * time.Second
+
+	cases := []struct {
+		path string
+		ok   bool
+	}{
+		{"/schemas.yaml#/Pet/properties/name", true},
+		{"/schemas.yaml#/Missing", false},
+		{"/raw/errors.json#/Error", true},
+		{"/raw/errors.json#/Pet", false},
+		// Hash routes of single-page apps are not JSON pointers
+		{"/app#/settings", true},
+	}
+	for _, c := range cases {
+		r := checkHTTP(context.Background(), client, Config{}, srv.URL+c.path)
+		if r.OK != c.ok {
+			t.Fatalf("%s: ok=%v err=%v, want ok=%v", c.path, r.OK, r.Err, c.ok)
+		}
+		if !c.ok && r.ErrorKind != ErrorKindJSONPointer {
+			t.Fatalf("%s: kind %q, want %q", c.path, r.ErrorKind, ErrorKindJSONPointer)
+		}
+		if r.Method != http.MethodGet {
+			t.Fatalf("%s: method %s, want GET to read the document", c.path, r.Method)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package web
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultRetryBaseDelay = 500 * time.Millisecond
+	defaultRetryMaxDelay  = 30 * time.Second
+)
+
+// retryable reports whether a failed attempt is worth repeating: timeouts,
+// dropped connections, rate limiting and gateway errors.
+func retryable(fr fetchResult, err error) bool {
+	if err != nil {
+		switch errorKindOf(err) {
+		case ErrorKindTimeoutConnect, ErrorKindTimeoutTLS, ErrorKindTimeoutHeader, ErrorKindTimeout, ErrorKindReset:
+			return true
+		}
+		return false
+	}
+	switch fr.Status {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	case http.StatusForbidden:
+		// GitHub and others signal an exhausted quota with 403
+		return fr.Header.Get("X-RateLimit-Remaining") == "0"
+	}
+	return false
+}
+
+// retryDelay returns how long to wait before attempt number attempt+1. A
+// server-provided delay wins over the backoff; ok is false when that delay
+// exceeds maxDelay and retrying is pointless.
+func retryDelay(fr fetchResult, attempt int, base, maxDelay time.Duration, now time.Time) (d time.Duration, ok bool) {
+	if base <= 0 {
+		base = defaultRetryBaseDelay
+	}
+	if maxDelay <= 0 {
+		maxDelay = defaultRetryMaxDelay
+	}
+	if d, found := serverDelay(fr.Header, now); found {
+		return d, d <= maxDelay
+	}
+	// Exponential backoff with jitter in [d/2, d]
+	d = base << (attempt - 1)
+	if d <= 0 || d > maxDelay {
+		d = maxDelay
+	}
+	half := d / 2
+	return half + rand.N(half+1), true
+}
+
+// serverDelay reads Retry-After (seconds or HTTP date) or X-RateLimit-Reset
+// (epoch seconds, or seconds from now for small values).
+func serverDelay(h http.Header, now time.Time) (time.Duration, bool) {
+	if h == nil {
+		return 0, false
+	}
+	if v := strings.TrimSpace(h.Get("Retry-After")); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+			return time.Duration(secs) * time.Second, true
+		}
+		if t, err := http.ParseTime(v); err == nil {
+			return max(t.Sub(now), 0), true
+		}
+	}
+	if v := strings.TrimSpace(h.Get("X-RateLimit-Reset")); v != "" {
+		if secs, err := strconv.ParseInt(v, 10, 64); err == nil && secs >= 0 {
+			if secs > 1_000_000_000 {
+				return max(time.Unix(secs, 0).Sub(now), 0), true
+			}
+			return time.Duration(secs) * time.Second, true
+		}
+	}
+	return 0, false
+}
+
+// sleepCtx waits for d or until ctx is done, reporting whether the full delay
+// elapsed.
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,100 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCheckHTTP_Retries(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := hits.Add(1)
+		switch r.URL.Path {
+		case "/flaky":
+			if n < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+		case "/limited":
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		case "/later":
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+	}))
+	defer srv.Close()
+
+	cfg := Config{MaxRetries: 3, RetryBaseDelay: time.Millisecond, RetryMaxDelay: time.Minute}
+	cases := []struct {
+		path     string
+		ok       bool
+		status   int
+		attempts int
+	}{
+		{"/flaky", true, 200, 3},
+		{"/limited", false, 429, 4},
+		{"/later", false, 429, 1},
+		{"/missing", false, 404, 1},
+	}
+	for _, c := range cases {
+		hits.Store(0)
+		r := checkHTTP(context.Background(), srv.Client(), cfg, srv.URL+c.path)
+		if r.OK != c.ok || r.Status != c.status || r.Attempts != c.attempts {
+			t.Fatalf("%s: ok=%v status=%d attempts=%d, want ok=%v status=%d attempts=%d", c.path, r.OK, r.Status, r.Attempts, c.ok, c.status, c.attempts)
+		}
+	}
+}
+
+func TestCheckHTTP_RetryCancel(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	cfg := Config{MaxRetries: 5, RetryBaseDelay: 10 * time.Second, RetryMaxDelay: time.Minute}
+	start := time.Now()
+	r := checkHTTP(ctx, srv.Client(), cfg, srv.URL)
+	if time.Since(start) > 2*time.Second {
+		t.Fatalf("retry wait was not cancelled")
+	}
+	if r.OK || r.Attempts != 1 {
+		t.Fatalf("expected one failed attempt, got ok=%v attempts=%d", r.OK, r.Attempts)
+	}
+}
+
+func TestServerDelay(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		header, value string
+		want          time.Duration
+	}{
+		{"Retry-After", "120", 2 * time.Minute},
+		{"Retry-After", now.Add(30 * time.Second).Format(http.TimeFormat), 30 * time.Second},
+		{"X-RateLimit-Reset", strconv.FormatInt(now.Add(time.Minute).Unix(), 10), time.Minute},
+		{"X-RateLimit-Reset", "15", 15 * time.Second},
+	}
+	for _, c := range cases {
+		h := http.Header{}
+		h.Set(c.header, c.value)
+		got, ok := serverDelay(h, now)
+		if !ok || got != c.want {
+			t.Fatalf("%s: %s => %v (%v), want %v", c.header, c.value, got, ok, c.want)
+		}
+	}
+	if _, ok := serverDelay(http.Header{}, now); ok {
+		t.Fatalf("expected no delay without headers")
+	}
+}
@@ -0,0 +1,50 @@
+package web
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// RewriteRule maps URLs matching a regular expression to a replacement before
+// they are fetched. Replace may reference capture groups ($1, ${name}).
+type RewriteRule struct {
+	Match   string `json:"match"`
+	Replace string `json:"replace"`
+
+	re *regexp.Regexp
+}
+
+// CompileRewrites validates rules and returns copies with their patterns compiled.
+func CompileRewrites(rules []RewriteRule) ([]RewriteRule, error) {
+	out := make([]RewriteRule, 0, len(rules))
+	for i, r := range rules {
+		if r.Match == "" {
+			return nil, fmt.Errorf("rewrites[%d]: match is required", i)
+		}
+		re, err := regexp.Compile(r.Match)
+		if err != nil {
+			return nil, fmt.Errorf("rewrites[%d]: %w", i, err)
+		}
+		r.re = re
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+// ApplyRewrites returns the URL produced by the first matching rule, or u
+// unchanged if no rule matches.
+func ApplyRewrites(rules []RewriteRule, u string) string {
+	for _, r := range rules {
+		re := r.re
+		if re == nil {
+			var err error
+			if re, err = regexp.Compile(r.Match); err != nil {
+				continue
+			}
+		}
+		if re.MatchString(u) {
+			return re.ReplaceAllString(u, r.Replace)
+		}
+	}
+	return u
+}
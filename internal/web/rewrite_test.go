@@ -0,0 +1,28 @@
+package web
+
+import "testing"
+
+func TestApplyRewrites(t *testing.T) {
+	rules, err := CompileRewrites([]RewriteRule{
+		{Match: `^https://docs\.ourco\.com/`, Replace: "https://staging-docs.ourco.com/"},
+		{Match: `^https://github\.com/([^/]+)/([^/]+)/blob/(.+)$`, Replace: "https://raw.githubusercontent.com/$1/$2/$3"},
+	})
+	if err != nil {
+		t.Fatalf("CompileRewrites error: %v", err)
+	}
+
+	cases := map[string]string{
+		"https://docs.ourco.com/guide":                   "https://staging-docs.ourco.com/guide",
+		"https://github.com/owner/repo/blob/main/a/b.md": "https://raw.githubusercontent.com/owner/repo/main/a/b.md",
+		"https://example.com/unchanged":                  "https://example.com/unchanged",
+	}
+	for in, want := range cases {
+		if got := ApplyRewrites(rules, in); got != want {
+			t.Fatalf("ApplyRewrites(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	if _, err := CompileRewrites([]RewriteRule{{Match: "("}}); err == nil {
+		t.Fatalf("expected invalid pattern to fail compilation")
+	}
+}
@@ -0,0 +1,217 @@
+package web
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// defaultHostConcurrency caps parallel checks against one host unless a host
+// entry sets maxConcurrency.
+const defaultHostConcurrency = 4
+
+// hostQueue holds the pending URLs for one host.
+type hostQueue struct {
+	host   string
+	urls   []string
+	active int
+	limit  int
+}
+
+// scheduler hands out URLs round-robin across hosts, skipping hosts that are
+// at their concurrency limit, so a slow host holds at most its own slots.
+type scheduler struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	queues []*hostQueue
+	next   int
+	queued int
+	done   bool
+	stop   func() bool
+}
+
+// newScheduler groups urls by the host they are requested from, after
+// rewrites. Non-network URLs (file, data, mailto) share one queue limited only
+// by the worker count.
+func newScheduler(ctx context.Context, urls []string, cfg Config, workers int) *scheduler {
+	s := &scheduler{}
+	s.cond = sync.NewCond(&s.mu)
+	byHost := make(map[string]*hostQueue)
+	for _, u := range urls {
+		host := ""
+		target := ApplyRewrites(cfg.Rewrites, u)
+		if scheme := urlScheme(target); scheme == "http" || scheme == "https" || scheme == "ftp" {
+			host = hostnameOf(target)
+		}
+		q, ok := byHost[host]
+		if !ok {
+			q = &hostQueue{host: host, limit: workers}
+			if host != "" {
+				q.limit = hostConcurrency(cfg.Hosts, host)
+			}
+			byHost[host] = q
+			s.queues = append(s.queues, q)
+		}
+		q.urls = append(q.urls, u)
+		s.queued++
+	}
+	s.stop = context.AfterFunc(ctx, func() {
+		s.mu.Lock()
+		s.done = true
+		s.mu.Unlock()
+		s.cond.Broadcast()
+	})
+	return s
+}
+
+func hostConcurrency(hosts []HostConfig, hostname string) int {
+	for _, h := range hostConfigs(hosts, hostname) {
+		if h.MaxConcurrency > 0 {
+			return h.MaxConcurrency
+		}
+	}
+	return defaultHostConcurrency
+}
+
+// acquire blocks until a URL whose host has a free slot is available. It
+// returns false once every URL has been handed out or the context ends.
+func (s *scheduler) acquire() (string, *hostQueue, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for {
+		if s.done || s.queued == 0 {
+			return "", nil, false
+		}
+		for i := range s.queues {
+			q := s.queues[(s.next+i)%len(s.queues)]
+			if len(q.urls) == 0 || q.active >= q.limit {
+				continue
+			}
+			u := q.urls[0]
+			q.urls = q.urls[1:]
+			q.active++
+			s.queued--
+			s.next = (s.next + i + 1) % len(s.queues)
+			return u, q, true
+		}
+		s.cond.Wait()
+	}
+}
+
+// close detaches the scheduler from its context.
+func (s *scheduler) close() { s.stop() }
+
+// release frees the host slot taken by acquire.
+func (s *scheduler) release(q *hostQueue) {
+	s.mu.Lock()
+	q.active--
+	s.mu.Unlock()
+	s.cond.Broadcast()
+}
+
+// limiter spaces events at least interval apart (no bursts).
+type limiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func newLimiter(rps float64) *limiter {
+	if rps <= 0 {
+		return nil
+	}
+	return &limiter{interval: time.Duration(float64(time.Second) / rps)}
+}
+
+// wait blocks until the next slot, or returns the context error.
+func (l *limiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next
+	if at.Before(now) {
+		at = now
+	}
+	l.next = at.Add(l.interval)
+	l.mu.Unlock()
+	if d := time.Until(at); d > 0 && !sleepCtx(ctx, d) {
+		l.mu.Lock()
+		// Give the slot back unless a later one has been reserved since
+		if l.next.Equal(at.Add(l.interval)) {
+			l.next = at
+		}
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+	return nil
+}
+
+// rateLimitTransport applies the global and per-host request rates to every
+// request, including retries and redirect hops. The request timeout starts
+// once a request has its slot, so waiting in line never times a request out.
+type rateLimitTransport struct {
+	base    http.RoundTripper
+	global  *limiter
+	hosts   []HostConfig
+	timeout time.Duration
+
+	mu     sync.Mutex
+	byHost map[string]*limiter
+}
+
+func newRateLimitTransport(base http.RoundTripper, cfg Config) *rateLimitTransport {
+	return &rateLimitTransport{base: base, global: newLimiter(cfg.GlobalRPS), hosts: cfg.Hosts, timeout: cfg.RequestTimeout, byHost: make(map[string]*limiter)}
+}
+
+func (t *rateLimitTransport) hostLimiter(hostname string) *limiter {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	l, ok := t.byHost[hostname]
+	if !ok {
+		for _, h := range hostConfigs(t.hosts, hostname) {
+			if h.RPS > 0 {
+				l = newLimiter(h.RPS)
+				break
+			}
+		}
+		t.byHost[hostname] = l
+	}
+	return l
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.hostLimiter(req.URL.Hostname()).wait(req.Context()); err != nil {
+		return nil, &kindError{ErrorKindRateLimitWait, "canceled while waiting for a rate limit slot", err}
+	}
+	if err := t.global.wait(req.Context()); err != nil {
+		return nil, &kindError{ErrorKindRateLimitWait, "canceled while waiting for a rate limit slot", err}
+	}
+	if t.timeout <= 0 {
+		return t.base.RoundTrip(req)
+	}
+	ctx, cancel := context.WithTimeout(req.Context(), t.timeout)
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	// The timeout also covers reading the body
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelOnClose releases a request's timeout context with its body.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,169 @@
+package web
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestScheduler_Interleaves(t *testing.T) {
+	urls := []string{
+		"https://a.example/1", "https://a.example/2", "https://a.example/3",
+		"https://b.example/1", "file:///tmp/x",
+	}
+	s := newScheduler(context.Background(), urls, Config{}, 8)
+	defer s.close()
+	var got []string
+	for {
+		u, _, ok := s.acquire()
+		if !ok {
+			break
+		}
+		got = append(got, u)
+	}
+	want := "https://a.example/1 https://b.example/1 file:///tmp/x https://a.example/2 https://a.example/3"
+	if strings.Join(got, " ") != want {
+		t.Fatalf("order %v, want %s", got, want)
+	}
+}
+
+func TestCheckURLs_HostLimits(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		active  = map[string]int{}
+		peak    = map[string]int{}
+		started []time.Time
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := strings.Split(r.Host, ":")[0]
+		mu.Lock()
+		active[host]++
+		peak[host] = max(peak[host], active[host])
+		if host == "localhost" {
+			started = append(started, time.Now())
+		}
+		mu.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		mu.Lock()
+		active[host]--
+		mu.Unlock()
+	}))
+	defer srv.Close()
+	port := srv.URL[strings.LastIndex(srv.URL, ":")+1:]
+
+	var urls []string
+	for i := 0; i < 6; i++ {
+		urls = append(urls, fmt.Sprintf("http://127.0.0.1:%s/%d", port, i), fmt.Sprintf("http://localhost:%s/%d", port, i))
+	}
+	cfg := Config{
+		MaxConcurrency: 8,
+		RequestTimeout: 5 * time.Second,
+		Hosts: []HostConfig{
+			{Match: "127.0.0.1", MaxConcurrency: 2},
+			{Match: "localhost", RPS: 50},
+		},
+	}
+	out := make(chan Result, len(urls))
+	go CheckURLs(context.Background(), urls, nil, out, nil, cfg)
+	n := 0
+	for r := range out {
+		n++
+		if !r.OK {
+			t.Fatalf("%s failed: %v", r.URL, r.Err)
+		}
+	}
+	if n != len(urls) {
+		t.Fatalf("expected %d results, got %d", len(urls), n)
+	}
+	if peak["127.0.0.1"] > 2 {
+		t.Fatalf("127.0.0.1 saw %d concurrent requests, cap is 2", peak["127.0.0.1"])
+	}
+	// Six requests at 50 rps take at least 100ms; arrival times jitter, so
+	// only the overall spread is checked, with a loose tolerance
+	if len(started) != 6 {
+		t.Fatalf("localhost saw %d requests, want 6", len(started))
+	}
+	if spread := started[len(started)-1].Sub(started[0]); spread < 70*time.Millisecond {
+		t.Fatalf("6 localhost requests at 50 rps arrived within %v", spread)
+	}
+}
+
+func TestScheduler_GroupsByRewrittenHost(t *testing.T) {
+	rules, err := CompileRewrites([]RewriteRule{{Match: `^https://old\.example/`, Replace: "https://new.example/"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	urls := []string{"https://old.example/1", "https://new.example/2", "https://other.example/3"}
+	s := newScheduler(context.Background(), urls, Config{Rewrites: rules, Hosts: []HostConfig{{Match: "new.example", MaxConcurrency: 1}}}, 8)
+	defer s.close()
+	if len(s.queues) != 2 || s.queues[0].host != "new.example" || len(s.queues[0].urls) != 2 || s.queues[0].limit != 1 {
+		t.Fatalf("queues %+v, want old.example and new.example to share a queue with limit 1", s.queues[0])
+	}
+}
+
+func TestLimiter_ReservesSpacedSlots(t *testing.T) {
+	l := newLimiter(1000)
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if err := l.wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The fifth wait reserved the slot 4ms after the first, so next is 5ms on
+	if reserved := l.next.Sub(start); reserved < 5*time.Millisecond {
+		t.Fatalf("5 slots at 1000 rps reserved only %v ahead", reserved)
+	}
+}
+
+func TestLimiter_Cancel(t *testing.T) {
+	l := newLimiter(0.1)
+	if err := l.wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := l.wait(ctx); err == nil {
+		t.Fatalf("expected the second wait to be cancelled")
+	}
+	// The cancelled wait gave its slot back: next is the first slot's end
+	if reserved := time.Until(l.next); reserved > 10*time.Second {
+		t.Fatalf("cancelled wait kept its reservation, next slot in %v", reserved)
+	}
+}
+
+func TestRateLimitTransport_CancelledWait(t *testing.T) {
+	rt := newRateLimitTransport(http.DefaultTransport, Config{Hosts: []HostConfig{{Match: "slow.example", RPS: 0.1}}})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	// The first slot is taken up front, so the request waits 10s for its own
+	_ = rt.hostLimiter("slow.example").wait(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://slow.example/", nil)
+	_, err := rt.RoundTrip(req)
+	if kind := errorKindOf(err); kind != ErrorKindRateLimitWait {
+		t.Fatalf("kind %q (%v), want %q", kind, err, ErrorKindRateLimitWait)
+	}
+}
+
+func TestCheckURLs_RateWaitOutsideTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	var urls []string
+	for i := 0; i < 6; i++ {
+		urls = append(urls, fmt.Sprintf("%s/%d", srv.URL, i))
+	}
+	// Six requests at 10 rps take 500ms, longer than the 300ms timeout of any
+	// single one
+	cfg := Config{MaxConcurrency: 6, RequestTimeout: 300 * time.Millisecond, Hosts: []HostConfig{{Match: "127.0.0.1", RPS: 10, MaxConcurrency: 6}}}
+	out := make(chan Result, len(urls))
+	go CheckURLs(context.Background(), urls, nil, out, nil, cfg)
+	for r := range out {
+		if !r.OK {
+			t.Fatalf("%s failed while waiting for its slot: %s %v", r.URL, r.ErrorKind, r.Err)
+		}
+	}
+}
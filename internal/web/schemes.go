@@ -0,0 +1,109 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// SchemeChecker validates URLs of one scheme. Implementations fill OK, Status,
+// Err and Method; CheckURLs fills in the URL, sources and error message.
+type SchemeChecker interface {
+	CheckURL(ctx context.Context, raw string) Result
+}
+
+// SchemeCheckerFunc adapts a function to SchemeChecker.
+type SchemeCheckerFunc func(ctx context.Context, raw string) Result
+
+func (f SchemeCheckerFunc) CheckURL(ctx context.Context, raw string) Result { return f(ctx, raw) }
+
+var (
+	registeredMu      sync.RWMutex
+	registeredSchemes = make(map[string]SchemeChecker)
+)
+
+// RegisterScheme installs a checker for scheme (without the trailing ':') for
+// all subsequent CheckURLs runs. It overrides built-in and configured checkers.
+func RegisterScheme(scheme string, c SchemeChecker) {
+	registeredMu.Lock()
+	defer registeredMu.Unlock()
+	registeredSchemes[strings.ToLower(scheme)] = c
+}
+
+// PatternSchemeChecker accepts a URL when it matches Pattern, for custom
+// schemes (e.g. vscode://, slack://) that cannot be dereferenced.
+type PatternSchemeChecker struct {
+	Pattern *regexp.Regexp
+}
+
+func (p PatternSchemeChecker) CheckURL(_ context.Context, raw string) Result {
+	if p.Pattern != nil && !p.Pattern.MatchString(raw) {
+		return Result{Method: "PATTERN", Err: simpleError("does not match pattern " + p.Pattern.String())}
+	}
+	return Result{OK: true, Method: "PATTERN"}
+}
+
+// schemeRegistry resolves the checker for each URL in one CheckURLs run.
+type schemeRegistry map[string]SchemeChecker
+
+// newSchemeRegistry wires the built-in checkers to this run's HTTP client and
+// settings, then layers configured and registered checkers on top.
+func newSchemeRegistry(cfg Config, client *http.Client) schemeRegistry {
+	var mailDomains *mailDomainChecker
+	if cfg.CheckMX {
+		mailDomains = newMailDomainChecker(cfg.DNSServer)
+	}
+	// One checker per run, so GitHub API responses are shared across links
+	hc := newHTTPChecker(client, cfg)
+	httpChecker := SchemeCheckerFunc(hc.check)
+	reg := schemeRegistry{
+		"http":  httpChecker,
+		"https": httpChecker,
+		"file": SchemeCheckerFunc(func(_ context.Context, raw string) Result {
+			ok, err := checkFileURL(raw)
+			return Result{OK: ok, Err: err, Method: "FILE"}
+		}),
+		"mailto": SchemeCheckerFunc(func(ctx context.Context, raw string) Result {
+			ok, err := checkMailto(ctx, raw, mailDomains)
+			return Result{OK: ok, Err: err, Method: "MAILTO", ErrorKind: errorKindOf(err)}
+		}),
+		"ftp": SchemeCheckerFunc(func(ctx context.Context, raw string) Result {
+			ok, status, err := checkFTPURL(ctx, raw, cfg.RequestTimeout)
+			return Result{OK: ok, Status: status, Err: err, Method: "FTP"}
+		}),
+		"data": SchemeCheckerFunc(func(_ context.Context, raw string) Result {
+			ok, err := checkDataURL(raw)
+			return Result{OK: ok, Err: err, Method: "DATA"}
+		}),
+	}
+	for scheme, c := range cfg.Schemes {
+		reg[strings.ToLower(scheme)] = c
+	}
+	registeredMu.RLock()
+	for scheme, c := range registeredSchemes {
+		reg[scheme] = c
+	}
+	registeredMu.RUnlock()
+	return reg
+}
+
+// check dispatches raw to the checker for its scheme. URLs with a scheme
+// nobody handles are reported as skipped rather than dropped.
+func (reg schemeRegistry) check(ctx context.Context, raw string) Result {
+	scheme := urlScheme(raw)
+	c, ok := reg[scheme]
+	if !ok {
+		return Result{Skipped: true, Method: "SKIP", Err: simpleError("unsupported scheme: " + scheme)}
+	}
+	return c.CheckURL(ctx, raw)
+}
+
+func urlScheme(raw string) string {
+	scheme, _, ok := strings.Cut(raw, ":")
+	if !ok {
+		return ""
+	}
+	return strings.ToLower(scheme)
+}
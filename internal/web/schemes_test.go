@@ -0,0 +1,63 @@
+package web
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestCheckURLs_SchemeDispatch(t *testing.T) {
+	RegisterScheme("test", SchemeCheckerFunc(func(_ context.Context, raw string) Result {
+		return Result{OK: raw == "test://good", Method: "TEST"}
+	}))
+	defer func() {
+		registeredMu.Lock()
+		delete(registeredSchemes, "test")
+		registeredMu.Unlock()
+	}()
+
+	urls := []string{
+		"data:text/plain;base64,aGVsbG8=",
+		"data:text/plain;base64,@@@",
+		"DATA:,hello%20world",
+		"test://good",
+		"test://bad",
+		"vscode://file/tmp/x",
+		"slack://channel?id=1",
+	}
+	cfg := Config{
+		MaxConcurrency: 2,
+		RequestTimeout: time.Second,
+		Schemes:        map[string]SchemeChecker{"vscode": PatternSchemeChecker{Pattern: regexp.MustCompile(`^vscode://file/`)}},
+	}
+	out := make(chan Result, len(urls))
+	go CheckURLs(context.Background(), urls, nil, out, nil, cfg)
+
+	got := make(map[string]Result)
+	for r := range out {
+		got[r.URL] = r
+	}
+	want := map[string]struct {
+		ok      bool
+		skipped bool
+		method  string
+	}{
+		"data:text/plain;base64,aGVsbG8=": {true, false, "DATA"},
+		"data:text/plain;base64,@@@":      {false, false, "DATA"},
+		"DATA:,hello%20world":             {true, false, "DATA"},
+		"test://good":                     {true, false, "TEST"},
+		"test://bad":                      {false, false, "TEST"},
+		"vscode://file/tmp/x":             {true, false, "PATTERN"},
+		"slack://channel?id=1":            {false, true, "SKIP"},
+	}
+	for u, w := range want {
+		r, ok := got[u]
+		if !ok {
+			t.Fatalf("no result for %s", u)
+		}
+		if r.OK != w.ok || r.Skipped != w.skipped || r.Method != w.method {
+			t.Fatalf("%s: ok=%v skipped=%v method=%s err=%s, want ok=%v skipped=%v method=%s", u, r.OK, r.Skipped, r.Method, r.ErrMsg, w.ok, w.skipped, w.method)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package web
+
+import (
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// SelfRepoConfig identifies the repository being scanned so links to its own
+// files on GitHub can be checked against the local checkout.
+type SelfRepoConfig struct {
+	Owner, Repo string
+	// Root is the checkout's top-level directory.
+	Root string
+	// Refs are the branch names the working tree stands in for; links to other
+	// refs (tags, SHAs, old branches) are checked remotely.
+	Refs []string
+	// Hosts are the web hosts serving the repository (default github.com).
+	Hosts []string
+}
+
+// selfRepoResolver maps blob/tree/raw URLs of the scanned repository to paths
+// in the working tree.
+type selfRepoResolver struct {
+	cfg   SelfRepoConfig
+	refs  []string
+	hosts []string
+}
+
+func newSelfRepoResolver(cfg SelfRepoConfig) *selfRepoResolver {
+	refs := append([]string(nil), cfg.Refs...)
+	// Longest first so "release/1.x" wins over "release"
+	sort.SliceStable(refs, func(i, j int) bool { return len(refs[i]) > len(refs[j]) })
+	hosts := cfg.Hosts
+	if len(hosts) == 0 {
+		hosts = []string{"github.com", "www.github.com"}
+	}
+	return &selfRepoResolver{cfg: cfg, refs: refs, hosts: hosts}
+}
+
+// resolve returns handled=false for URLs outside the repository or at refs
+// the working tree does not represent.
+func (s *selfRepoResolver) resolve(raw string) (res Result, handled bool) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return Result{}, false
+	}
+	parts := strings.Split(strings.Trim(u.EscapedPath(), "/"), "/")
+	for i, p := range parts {
+		if v, err := url.PathUnescape(p); err == nil {
+			parts[i] = v
+		}
+	}
+	var kind string
+	var rest []string
+	switch host := strings.ToLower(u.Hostname()); {
+	case host == "raw.githubusercontent.com" && len(parts) >= 3:
+		kind, rest = "raw", parts[2:]
+		if len(rest) >= 2 && rest[0] == "refs" && rest[1] == "heads" {
+			rest = rest[2:]
+		}
+	case s.webHost(host) && len(parts) >= 4 && (parts[2] == "blob" || parts[2] == "tree" || parts[2] == "raw"):
+		kind, rest = parts[2], parts[3:]
+	default:
+		return Result{}, false
+	}
+	if !strings.EqualFold(parts[0], s.cfg.Owner) || !strings.EqualFold(strings.TrimSuffix(parts[1], ".git"), s.cfg.Repo) {
+		return Result{}, false
+	}
+	joined := strings.Join(rest, "/")
+	var rel string
+	matched := false
+	for _, ref := range s.refs {
+		if joined == ref || strings.HasPrefix(joined, ref+"/") {
+			rel, matched = strings.TrimPrefix(strings.TrimPrefix(joined, ref), "/"), true
+			break
+		}
+	}
+	if !matched {
+		return Result{}, false
+	}
+
+	res = Result{Method: "LOCAL"}
+	clean := path.Clean("/" + rel)
+	local := filepath.Join(s.cfg.Root, filepath.FromSlash(clean))
+	st, err := os.Stat(local)
+	if err != nil {
+		res.Err = simpleError("not in checkout: " + strings.TrimPrefix(clean, "/"))
+		return res, true
+	}
+	if kind != "tree" && st.IsDir() {
+		res.Err = simpleError("is a directory in checkout: " + strings.TrimPrefix(clean, "/"))
+		return res, true
+	}
+	if last, ok := lineRangeEnd(u.Fragment); ok && !st.IsDir() {
+		data, err := os.ReadFile(local)
+		if err != nil {
+			res.Err = err
+			return res, true
+		}
+		if n := countLines(data); last > n {
+			res.Err = lineRangeError(last, n)
+			return res, true
+		}
+	}
+	res.OK = true
+	return res, true
+}
+
+func (s *selfRepoResolver) webHost(host string) bool {
+	for _, h := range s.hosts {
+		if strings.EqualFold(h, host) {
+			return true
+		}
+	}
+	return false
+}
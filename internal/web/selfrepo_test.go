@@ -0,0 +1,48 @@
+package web
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSelfRepoResolver(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "docs"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "docs", "guide.md"), []byte("a\nb\nc\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s := newSelfRepoResolver(SelfRepoConfig{Owner: "ourorg", Repo: "ourrepo", Root: root, Refs: []string{"main", "feature/docs"}})
+
+	cases := []struct {
+		url     string
+		handled bool
+		ok      bool
+	}{
+		{"https://github.com/ourorg/ourrepo/blob/main/docs/guide.md", true, true},
+		{"https://github.com/OurOrg/ourrepo/blob/main/docs/guide.md#L2-L3", true, true},
+		{"https://github.com/ourorg/ourrepo/blob/main/docs/guide.md#L9", true, false},
+		{"https://github.com/ourorg/ourrepo/blob/feature/docs/docs/guide.md", true, true},
+		{"https://github.com/ourorg/ourrepo/tree/main/docs", true, true},
+		{"https://github.com/ourorg/ourrepo/blob/main/docs", true, false},
+		{"https://github.com/ourorg/ourrepo/raw/main/docs/guide.md", true, true},
+		{"https://raw.githubusercontent.com/ourorg/ourrepo/main/docs/guide.md", true, true},
+		{"https://raw.githubusercontent.com/ourorg/ourrepo/refs/heads/main/docs/guide.md", true, true},
+		{"https://github.com/ourorg/ourrepo/blob/main/docs/renamed.md", true, false},
+		{"https://github.com/ourorg/ourrepo/blob/main/../../etc/passwd", true, false},
+		{"https://github.com/ourorg/ourrepo/blob/v1.0/docs/guide.md", false, false},
+		{"https://github.com/ourorg/other/blob/main/docs/guide.md", false, false},
+		{"https://github.com/ourorg/ourrepo/issues/1", false, false},
+	}
+	for _, c := range cases {
+		r, handled := s.resolve(c.url)
+		if handled != c.handled || r.OK != c.ok {
+			t.Fatalf("%s: handled=%v ok=%v err=%v, want handled=%v ok=%v", c.url, handled, r.OK, r.Err, c.handled, c.ok)
+		}
+		if handled && r.Method != "LOCAL" {
+			t.Fatalf("%s: method %q", c.url, r.Method)
+		}
+	}
+}
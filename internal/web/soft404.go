@@ -0,0 +1,251 @@
+package web
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Soft404Config enables heuristics for pages that answer 200 but are really
+// "not found" pages.
+type Soft404Config struct {
+	Enabled bool `json:"enabled"`
+	// Warn reports matches as warnings instead of failures.
+	Warn bool `json:"warn,omitempty"`
+	// Phrases extend the built-in phrases matched (case-insensitively) against
+	// the page title and its main heading.
+	Phrases []string `json:"phrases,omitempty"`
+	// CompareRandom fetches a random path on each host once and flags pages
+	// that look like that response.
+	CompareRandom bool `json:"compareRandom,omitempty"`
+}
+
+// soft404TitlePhrases must make up the whole <title>, or its leading segment,
+// once "404" and "error" are dropped: "404 Not Found | Example" matches, while
+// "Error: NotFoundException – API reference" and issue titles do not.
+var soft404TitlePhrases = []string{"not found", "page not found", "does not exist", "doesn't exist", "no longer available"}
+
+// soft404HeadingPhrases are longer; a sentence of the first <h1> only has to
+// end with one.
+var soft404HeadingPhrases = []string{
+	"page not found",
+	"page you requested could not be found",
+	"page you were looking for doesn't exist",
+	"page you are looking for does not exist",
+	"this page doesn't exist",
+	"this page does not exist",
+}
+
+var (
+	htmlTitleRegex    = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	htmlH1Regex       = regexp.MustCompile(`(?is)<h1[^>]*>(.*?)</h1>`)
+	htmlTagRegex      = regexp.MustCompile(`<[^>]*>`)
+	titleSegmentRegex = regexp.MustCompile(`\s+[|·•–—-]\s+|:\s+`)
+	sentenceEndRegex  = regexp.MustCompile(`[.!?]+`)
+	nonWordRegex      = regexp.MustCompile(`[^\pL\pN']+`)
+)
+
+// indexPageRegex matches paths that servers canonicalize to their directory.
+var indexPageRegex = regexp.MustCompile(`(?i)/index\.(?:html?|php)$`)
+
+// soft404Detector holds per-run state: one random-path probe per host.
+type soft404Detector struct {
+	cfg     Soft404Config
+	phrases []string
+
+	mu     sync.Mutex
+	probes map[string]*soft404Probe
+}
+
+// soft404Probe is what a host returned for a path that cannot exist. valid is
+// false when the host answered it with an error status, as it should.
+type soft404Probe struct {
+	once     sync.Once
+	valid    bool
+	finalURL string
+	title    string
+	size     int
+}
+
+func newSoft404Detector(cfg Soft404Config) *soft404Detector {
+	d := &soft404Detector{cfg: cfg, probes: make(map[string]*soft404Probe)}
+	for _, p := range cfg.Phrases {
+		if p = strings.ToLower(strings.TrimSpace(p)); p != "" {
+			d.phrases = append(d.phrases, p)
+		}
+	}
+	return d
+}
+
+// detect returns why the successful response fr for raw looks like a missing
+// page, or "" if it does not.
+func (d *soft404Detector) detect(ctx context.Context, client *http.Client, raw string, fr fetchResult) string {
+	final := raw
+	if n := len(fr.Redirects); n > 0 {
+		final = fr.Redirects[n-1].Location
+	}
+	// A deep link landing on the home page is only suspicious if the host
+	// sends paths that cannot exist there too
+	if isRootPath(final) && !isRootPath(raw) && !isIndexPage(raw) {
+		if p := d.probe(ctx, client, raw); p.valid && p.finalURL == final {
+			return "redirected to the site root, like a random path"
+		}
+	}
+	title := pageTitle(fr.Body)
+	if d.titleMatches(title) {
+		return fmt.Sprintf("title %q", title)
+	}
+	if h1, ok := d.headingMatches(fr.Body); ok {
+		return fmt.Sprintf("heading %q", h1)
+	}
+	if d.cfg.CompareRandom {
+		if p := d.probe(ctx, client, raw); p.valid && looksLike(p, final, title, len(fr.Body)) {
+			return "same response as a random path on the host"
+		}
+	}
+	return ""
+}
+
+func (d *soft404Detector) probe(ctx context.Context, client *http.Client, raw string) *soft404Probe {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return &soft404Probe{}
+	}
+	key := u.Scheme + "://" + u.Host
+	d.mu.Lock()
+	p, ok := d.probes[key]
+	if !ok {
+		p = &soft404Probe{}
+		d.probes[key] = p
+	}
+	d.mu.Unlock()
+	p.once.Do(func() {
+		var b [8]byte
+		_, _ = rand.Read(b[:])
+		probeURL := key + "/slinky-" + hex.EncodeToString(b[:])
+		fr, err := fetch(ctx, client, http.MethodGet, probeURL, 0, 0)
+		if err != nil || fr.Status >= 300 {
+			return
+		}
+		p.valid = true
+		p.finalURL = probeURL
+		if n := len(fr.Redirects); n > 0 {
+			p.finalURL = fr.Redirects[n-1].Location
+		}
+		p.title = pageTitle(fr.Body)
+		p.size = len(fr.Body)
+	})
+	return p
+}
+
+// looksLike compares a page with the random-path response: both redirected to
+// the same place, or same title and a body size within 10%.
+func looksLike(p *soft404Probe, final, title string, size int) bool {
+	if p.finalURL == final && !strings.Contains(p.finalURL, "/slinky-") {
+		return true
+	}
+	if title == "" || title != p.title {
+		return false
+	}
+	diff := size - p.size
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff*10 <= max(size, p.size)
+}
+
+// titleMatches reports whether the whole title or its leading segment is a
+// "not found" phrase, possibly with "404" or "error" around it.
+func (d *soft404Detector) titleMatches(title string) bool {
+	if title == "" {
+		return false
+	}
+	segments := []string{title, titleSegmentRegex.Split(title, 2)[0]}
+	for _, seg := range segments {
+		words := normalizePhrase(seg)
+		if words == "" && strings.Contains(" "+normalizeWords(seg)+" ", " 404 ") {
+			return true
+		}
+		for _, p := range d.allPhrases() {
+			if words == p {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// headingMatches reports whether a sentence of the first <h1> ends with a
+// "not found" phrase.
+func (d *soft404Detector) headingMatches(body []byte) (string, bool) {
+	m := htmlH1Regex.FindSubmatch(body)
+	if m == nil {
+		return "", false
+	}
+	h1 := strings.Join(strings.Fields(html.UnescapeString(htmlTagRegex.ReplaceAllString(string(m[1]), " "))), " ")
+	for _, sentence := range sentenceEndRegex.Split(h1, -1) {
+		words := normalizePhrase(sentence)
+		if words == "" {
+			continue
+		}
+		for _, p := range d.allPhrases() {
+			if words == p || strings.HasSuffix(words, " "+p) {
+				return h1, true
+			}
+		}
+	}
+	return "", false
+}
+
+func (d *soft404Detector) allPhrases() []string {
+	out := make([]string, 0, len(soft404TitlePhrases)+len(soft404HeadingPhrases)+len(d.phrases))
+	for _, p := range append(append(append([]string(nil), soft404TitlePhrases...), soft404HeadingPhrases...), d.phrases...) {
+		out = append(out, normalizeWords(p))
+	}
+	return out
+}
+
+// normalizeWords lowercases s and reduces it to words separated by single
+// spaces.
+func normalizeWords(s string) string {
+	return strings.Join(strings.Fields(nonWordRegex.ReplaceAllString(strings.ToLower(s), " ")), " ")
+}
+
+// normalizePhrase is normalizeWords without the words "404" and "error".
+func normalizePhrase(s string) string {
+	var out []string
+	for _, w := range strings.Fields(normalizeWords(s)) {
+		if w != "404" && w != "error" {
+			out = append(out, w)
+		}
+	}
+	return strings.Join(out, " ")
+}
+
+func pageTitle(body []byte) string {
+	m := htmlTitleRegex.FindSubmatch(body)
+	if m == nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(html.UnescapeString(string(m[1]))), " ")
+}
+
+func isIndexPage(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && indexPageRegex.MatchString(u.Path)
+}
+
+func isRootPath(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	return (u.Path == "" || u.Path == "/") && u.RawQuery == ""
+}
@@ -0,0 +1,112 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCheckHTTP_Soft404(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html><head><title>Home</title></head><body>Welcome</body></html>"))
+	})
+	mux.HandleFunc("/real", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html><head><title>Installation guide</title></head><body>Run the installer and follow the prompts to finish setup.</body></html>"))
+	})
+	mux.HandleFunc("/titled", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html><head><title>Page Not Found | Example</title></head></html>"))
+	})
+	mux.HandleFunc("/issue", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html><head><title>Issue #14042 · org/repo</title></head><body>Fix the flaky build on arm64 runners.</body></html>"))
+	})
+	mux.HandleFunc("/rfc", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html><head><title>RFC 7404 - Using Only Link-Local Addressing</title></head><body>This document discusses the advantages.</body></html>"))
+	})
+	mux.HandleFunc("/error", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html><head><title>Error 404 | Example</title></head></html>"))
+	})
+	mux.HandleFunc("/custom", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html><body><h1>Hmm, we looked everywhere but came up empty.</h1></body></html>"))
+	})
+	mux.HandleFunc("/heading", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html><head><title>Example</title></head><body><h1>Oops! Sorry, the page you requested could not be found.</h1></body></html>"))
+	})
+	mux.HandleFunc("/exception", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html><head><title>Error: NotFoundException – API reference</title></head><body><h1>NotFoundException</h1><p>Thrown when the page not found handler runs.</p></body></html>"))
+	})
+	mux.HandleFunc("/module-issue", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html><head><title>Module not found when bundling · Issue #12 · org/repo</title></head><body><h1>Module not found when bundling</h1></body></html>"))
+	})
+	// This host answers random paths with its shell, not the home page, so a
+	// redirect to the root is a deliberate move
+	mux.HandleFunc("/moved", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/", http.StatusFound)
+	})
+	// Every other path is a catch-all that renders the same shell
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html><head><title>Example Docs</title></head><body>Loading " + r.URL.Path + "</body></html>"))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	cfg := Config{Soft404: Soft404Config{Enabled: true, CompareRandom: true, Phrases: []string{"came up empty"}}}
+	h := newHTTPChecker(srv.Client(), cfg)
+	cases := []struct {
+		path string
+		ok   bool
+	}{
+		{"/real", true},
+		{"/", true},
+		{"/titled", false},
+		{"/issue", true},
+		{"/rfc", true},
+		{"/error", false},
+		{"/heading", false},
+		{"/exception", true},
+		{"/module-issue", true},
+		{"/custom", false},
+		{"/moved", true},
+		{"/docs/missing-page", false},
+	}
+	for _, c := range cases {
+		r := h.check(context.Background(), srv.URL+c.path)
+		if r.OK != c.ok {
+			t.Fatalf("%s: ok=%v err=%v", c.path, r.OK, r.Err)
+		}
+		if !c.ok && r.ErrorKind != ErrorKindSoft404 {
+			t.Fatalf("%s: expected soft-404 kind, got %q", c.path, r.ErrorKind)
+		}
+	}
+
+	cfg.Soft404.Warn = true
+	r := checkHTTP(context.Background(), srv.Client(), cfg, srv.URL+"/titled")
+	if !r.OK || len(r.Warnings) != 1 || r.ErrorKind != ErrorKindSoft404 {
+		t.Fatalf("warn mode: ok=%v warnings=%v kind=%q", r.OK, r.Warnings, r.ErrorKind)
+	}
+}
+
+func TestCheckHTTP_Soft404RedirectToRoot(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html><head><title>Home</title></head></html>"))
+	})
+	mux.HandleFunc("/index.html", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/", http.StatusMovedPermanently)
+	})
+	// Unknown paths, including the random probe, bounce to the home page
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/", http.StatusFound)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	h := newHTTPChecker(srv.Client(), Config{Soft404: Soft404Config{Enabled: true}})
+	if r := h.check(context.Background(), srv.URL+"/docs/missing"); r.OK || r.ErrorKind != ErrorKindSoft404 {
+		t.Fatalf("/docs/missing: ok=%v kind=%q err=%v", r.OK, r.ErrorKind, r.Err)
+	}
+	if r := h.check(context.Background(), srv.URL+"/index.html"); !r.OK {
+		t.Fatalf("/index.html: canonicalized to / but failed: %v", r.Err)
+	}
+}
@@ -0,0 +1,170 @@
+package web
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// StatusPolicy accepts or rejects HTTP status codes. A policy with Host (a
+// hostname glob) or URL (a regular expression) applies only to matching links;
+// one with neither applies to all. Codes are given as "404", "200-299" or
+// "4xx". Within a policy Reject wins over Accept; a status the policy does not
+// mention falls through to the next one.
+type StatusPolicy struct {
+	Host   string   `json:"host,omitempty"`
+	URL    string   `json:"url,omitempty"`
+	Accept []string `json:"accept,omitempty"`
+	Reject []string `json:"reject,omitempty"`
+
+	re     *regexp.Regexp
+	accept []statusRange
+	reject []statusRange
+}
+
+type statusRange struct {
+	lo, hi int
+	text   string
+}
+
+// defaultStatusPolicy applies when no configured policy mentions a status:
+// success and redirects pass, and so do 401/403 because the resource exists
+// behind credentials.
+var defaultStatusPolicy = mustCompileStatusPolicy(StatusPolicy{Accept: []string{"200-399", "401", "403"}})
+
+// CompileStatusPolicies validates policies and returns compiled copies.
+func CompileStatusPolicies(policies []StatusPolicy) ([]StatusPolicy, error) {
+	out := make([]StatusPolicy, 0, len(policies))
+	for i, p := range policies {
+		cp, err := compileStatusPolicy(p)
+		if err != nil {
+			return nil, fmt.Errorf("status[%d]: %w", i, err)
+		}
+		out = append(out, cp)
+	}
+	return out, nil
+}
+
+func compileStatusPolicy(p StatusPolicy) (StatusPolicy, error) {
+	if p.Host != "" && p.URL != "" {
+		return p, fmt.Errorf("set host or url, not both")
+	}
+	p.Host = strings.ToLower(strings.TrimSpace(p.Host))
+	if p.URL != "" {
+		re, err := regexp.Compile(p.URL)
+		if err != nil {
+			return p, err
+		}
+		p.re = re
+	}
+	var err error
+	if p.accept, err = parseStatusRanges(p.Accept); err != nil {
+		return p, err
+	}
+	if p.reject, err = parseStatusRanges(p.Reject); err != nil {
+		return p, err
+	}
+	return p, nil
+}
+
+func mustCompileStatusPolicy(p StatusPolicy) StatusPolicy {
+	cp, err := compileStatusPolicy(p)
+	if err != nil {
+		panic(err)
+	}
+	return cp
+}
+
+func parseStatusRanges(specs []string) ([]statusRange, error) {
+	var out []statusRange
+	for _, spec := range specs {
+		s := strings.ToLower(strings.TrimSpace(spec))
+		var r statusRange
+		switch {
+		case len(s) == 3 && strings.HasSuffix(s, "xx") && s[0] >= '1' && s[0] <= '9':
+			r.lo = int(s[0]-'0') * 100
+			r.hi = r.lo + 99
+		case strings.Contains(s, "-"):
+			a, b, _ := strings.Cut(s, "-")
+			lo, err1 := strconv.Atoi(strings.TrimSpace(a))
+			hi, err2 := strconv.Atoi(strings.TrimSpace(b))
+			if err1 != nil || err2 != nil || lo > hi {
+				return nil, fmt.Errorf("invalid status range %q", spec)
+			}
+			r.lo, r.hi = lo, hi
+		default:
+			n, err := strconv.Atoi(s)
+			if err != nil {
+				return nil, fmt.Errorf("invalid status %q", spec)
+			}
+			r.lo, r.hi = n, n
+		}
+		r.text = s
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+func (p StatusPolicy) applies(raw, hostname string) bool {
+	switch {
+	case p.re != nil:
+		return p.re.MatchString(raw)
+	case p.Host != "":
+		return matchHost(p.Host, hostname)
+	}
+	return true
+}
+
+func (p StatusPolicy) scope() string {
+	switch {
+	case p.URL != "":
+		return "url " + p.URL
+	case p.Host != "":
+		return "host " + p.Host
+	}
+	return "global"
+}
+
+// decide returns whether status is acceptable and the rule that said so.
+func (p StatusPolicy) decide(status int) (ok bool, rule string, found bool) {
+	for _, r := range p.reject {
+		if status >= r.lo && status <= r.hi {
+			return false, p.scope() + ": reject " + r.text, true
+		}
+	}
+	for _, r := range p.accept {
+		if status >= r.lo && status <= r.hi {
+			return true, p.scope() + ": accept " + r.text, true
+		}
+	}
+	return false, "", false
+}
+
+// defaultRulePrefix starts the StatusRule of results the default policy
+// decided.
+const defaultRulePrefix = "default:"
+
+// StatusPolicyDecided reports whether a configured status policy, rather than
+// the default, decided res.
+func (res Result) StatusPolicyDecided() bool {
+	return res.StatusRule != "" && !strings.HasPrefix(res.StatusRule, defaultRulePrefix)
+}
+
+// evaluateStatus applies the configured policies in order, then the default.
+// Statuses no rule mentions are failures.
+func evaluateStatus(policies []StatusPolicy, raw string, status int) (bool, string) {
+	hostname := hostnameOf(raw)
+	for _, p := range policies {
+		if !p.applies(raw, hostname) {
+			continue
+		}
+		if ok, rule, found := p.decide(status); found {
+			return ok, rule
+		}
+	}
+	if ok, rule, found := defaultStatusPolicy.decide(status); found {
+		return ok, defaultRulePrefix + strings.TrimPrefix(rule, "global:")
+	}
+	return false, defaultRulePrefix + " not accepted"
+}
@@ -0,0 +1,70 @@
+package web
+
+import "testing"
+
+func TestEvaluateStatus(t *testing.T) {
+	policies, err := CompileStatusPolicies([]StatusPolicy{
+		{Host: "*.linkedin.com", Accept: []string{"999"}},
+		{URL: `^https://ourco\.com/`, Reject: []string{"401", "403"}},
+		{Reject: []string{"3xx"}, Accept: []string{"410"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		url    string
+		status int
+		ok     bool
+		rule   string
+	}{
+		{"https://www.linkedin.com/in/x", 999, true, "host *.linkedin.com: accept 999"},
+		{"https://example.com/", 999, false, "default: not accepted"},
+		{"https://ourco.com/private", 403, false, `url ^https://ourco\.com/: reject 403`},
+		{"https://example.com/private", 403, true, "default: accept 403"},
+		{"https://example.com/moved", 301, false, "global: reject 3xx"},
+		{"https://example.com/gone", 410, true, "global: accept 410"},
+		{"https://example.com/", 200, true, "default: accept 200-399"},
+		{"https://example.com/", 429, false, "default: not accepted"},
+	}
+	for _, c := range cases {
+		ok, rule := evaluateStatus(policies, c.url, c.status)
+		if ok != c.ok || rule != c.rule {
+			t.Fatalf("%s %d: ok=%v rule=%q, want ok=%v rule=%q", c.url, c.status, ok, rule, c.ok, c.rule)
+		}
+	}
+}
+
+func TestCompileStatusPolicies_Invalid(t *testing.T) {
+	for _, p := range []StatusPolicy{
+		{Accept: []string{"abc"}},
+		{Accept: []string{"500-400"}},
+		{Host: "a.com", URL: "b"},
+		{URL: "("},
+	} {
+		if _, err := CompileStatusPolicies([]StatusPolicy{p}); err == nil {
+			t.Fatalf("expected error for %+v", p)
+		}
+	}
+}
+
+func TestResult_StatusPolicyDecided(t *testing.T) {
+	policies, err := CompileStatusPolicies([]StatusPolicy{{Host: "*.linkedin.com", Accept: []string{"999"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		url    string
+		status int
+		want   bool
+	}{
+		{"https://www.linkedin.com/in/x", 999, true},
+		{"https://www.linkedin.com/in/x", 200, false},
+		{"https://example.com/", 404, false},
+	}
+	for _, c := range cases {
+		_, rule := evaluateStatus(policies, c.url, c.status)
+		if got := (Result{StatusRule: rule}).StatusPolicyDecided(); got != c.want {
+			t.Errorf("%s %d (%s): decided by policy %v, want %v", c.url, c.status, rule, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package web
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestClassifyTLS(t *testing.T) {
+	cases := []struct {
+		err  error
+		kind ErrorKind
+	}{
+		{x509.CertificateInvalidError{Reason: x509.Expired}, ErrorKindTLSExpired},
+		{&tls.CertificateVerificationError{Err: x509.UnknownAuthorityError{}}, ErrorKindTLSUnknownAuthority},
+		{&url.Error{Op: "Head", URL: "https://a", Err: x509.HostnameError{Host: "a"}}, ErrorKindTLSHostname},
+		{tls.RecordHeaderError{Msg: "first record does not look like a TLS handshake"}, ErrorKindTLS},
+	}
+	for _, c := range cases {
+		ke, ok := classifyTLS(c.err)
+		if !ok || ke.kind != c.kind {
+			t.Errorf("classifyTLS(%v) = %v, want %s", c.err, ke, c.kind)
+		}
+	}
+	if _, ok := classifyTLS(context.DeadlineExceeded); ok {
+		t.Error("non-TLS error classified as TLS")
+	}
+}
+
+func TestTLSTransport(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.Config.ErrorLog = log.New(io.Discard, "", 0) // rejected handshakes are expected
+	srv.StartTLS()
+	defer srv.Close()
+	pool := x509.NewCertPool()
+	pool.AddCert(srv.Certificate())
+
+	check := func(cfg Config) Result {
+		client := &http.Client{Timeout: 2 * time.Second, Transport: newTLSTransport(&http.Transport{}, cfg.TLS)}
+		return checkHTTP(context.Background(), client, cfg, srv.URL)
+	}
+
+	if r := check(Config{}); r.OK || r.ErrorKind != ErrorKindTLSUnknownAuthority {
+		t.Fatalf("untrusted: ok=%v kind=%q err=%v", r.OK, r.ErrorKind, r.Err)
+	}
+	if r := check(Config{TLS: TLSConfig{RootCAs: pool}}); !r.OK || len(r.Warnings) != 0 {
+		t.Fatalf("extra CA: ok=%v err=%v warnings=%v", r.OK, r.Err, r.Warnings)
+	}
+	r := check(Config{TLS: TLSConfig{Hosts: []HostTLS{{Match: "127.0.0.1", InsecureSkipVerify: true}}}})
+	if !r.OK || len(r.Warnings) != 1 {
+		t.Fatalf("skip verify: ok=%v err=%v warnings=%v", r.OK, r.Err, r.Warnings)
+	}
+}
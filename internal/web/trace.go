@@ -0,0 +1,138 @@
+package web
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Timing breaks down where a check spent its time. Phases are summed over
+// every request of the final attempt, including redirect hops; a reused
+// connection contributes no DNS, connect or TLS time. Each request is timed
+// from when the transport asks for a connection, so waiting on slinky's own
+// rate limits is not counted; TTFB runs from there to the first response byte.
+type Timing struct {
+	DNS     time.Duration
+	Connect time.Duration
+	TLS     time.Duration
+	TTFB    time.Duration
+	Total   time.Duration
+}
+
+// MarshalJSON writes the phases in milliseconds.
+func (t Timing) MarshalJSON() ([]byte, error) {
+	ms := func(d time.Duration) float64 { return float64(d.Microseconds()) / 1000 }
+	return json.Marshal(struct {
+		DNS     float64 `json:"dnsMs"`
+		Connect float64 `json:"connectMs"`
+		TLS     float64 `json:"tlsMs"`
+		TTFB    float64 `json:"ttfbMs"`
+		Total   float64 `json:"totalMs"`
+	}{ms(t.DNS), ms(t.Connect), ms(t.TLS), ms(t.TTFB), ms(t.Total)})
+}
+
+func (t Timing) add(o Timing) Timing {
+	return Timing{
+		DNS:     t.DNS + o.DNS,
+		Connect: t.Connect + o.Connect,
+		TLS:     t.TLS + o.TLS,
+		TTFB:    t.TTFB + o.TTFB,
+		Total:   t.Total + o.Total,
+	}
+}
+
+// requestTrace records how far a request got and when, so a timeout can be
+// attributed to connecting, the TLS handshake or waiting for response
+// headers, and successful requests yield a Timing.
+type requestTrace struct {
+	mu         sync.Mutex
+	tlsStarted bool
+	tlsDone    bool
+	gotConn    bool
+
+	start, dnsStart, dnsDone, connectStart, connectDone, tlsStart, tlsEnd, firstByte time.Time
+}
+
+func newRequestTrace() *requestTrace {
+	return &requestTrace{}
+}
+
+func (t *requestTrace) clientTrace() *httptrace.ClientTrace {
+	mark := func(f func()) {
+		t.mu.Lock()
+		f()
+		t.mu.Unlock()
+	}
+	return &httptrace.ClientTrace{
+		// GetConn fires once the request reaches the base transport, after any
+		// rate limiter wrapping it has let it through
+		GetConn: func(string) {
+			mark(func() {
+				if t.start.IsZero() {
+					t.start = time.Now()
+				}
+			})
+		},
+		DNSStart: func(httptrace.DNSStartInfo) { mark(func() { t.dnsStart = time.Now() }) },
+		DNSDone:  func(httptrace.DNSDoneInfo) { mark(func() { t.dnsDone = time.Now() }) },
+		ConnectStart: func(string, string) {
+			mark(func() {
+				if t.connectStart.IsZero() {
+					t.connectStart = time.Now()
+				}
+			})
+		},
+		ConnectDone: func(string, string, error) { mark(func() { t.connectDone = time.Now() }) },
+		TLSHandshakeStart: func() {
+			mark(func() {
+				t.tlsStarted = true
+				t.tlsStart = time.Now()
+			})
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			mark(func() {
+				t.tlsDone = true
+				t.tlsEnd = time.Now()
+			})
+		},
+		GotConn:              func(httptrace.GotConnInfo) { mark(func() { t.gotConn = true }) },
+		GotFirstResponseByte: func() { mark(func() { t.firstByte = time.Now() }) },
+	}
+}
+
+// timing returns the phase durations seen so far.
+func (t *requestTrace) timing() Timing {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	span := func(from, to time.Time) time.Duration {
+		if from.IsZero() || to.IsZero() || to.Before(from) {
+			return 0
+		}
+		return to.Sub(from)
+	}
+	return Timing{
+		DNS:     span(t.dnsStart, t.dnsDone),
+		Connect: span(t.connectStart, t.connectDone),
+		TLS:     span(t.tlsStart, t.tlsEnd),
+		TTFB:    span(t.start, t.firstByte),
+		Total:   span(t.start, time.Now()),
+	}
+}
+
+// timeoutKind classifies a timeout by the last phase the request reached.
+func (t *requestTrace) timeoutKind() ErrorKind {
+	if t == nil {
+		return ErrorKindTimeout
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	switch {
+	case t.gotConn:
+		return ErrorKindTimeoutHeader
+	case t.tlsStarted && !t.tlsDone:
+		return ErrorKindTimeoutTLS
+	}
+	return ErrorKindTimeoutConnect
+}
@@ -0,0 +1,81 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckHTTP_TimingAndSlowLinks(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(150 * time.Millisecond)
+		}
+	}))
+	defer srv.Close()
+	client := srv.Client()
+	client.Timeout = 2 * time.Second
+	cfg := Config{SlowThreshold: 100 * time.Millisecond}
+
+	r := checkHTTP(context.Background(), client, cfg, srv.URL+"/slow")
+	if !r.OK || r.Timing == nil {
+		t.Fatalf("/slow: ok=%v timing=%v err=%v", r.OK, r.Timing, r.Err)
+	}
+	if r.Timing.TTFB < 150*time.Millisecond || r.Timing.Total < r.Timing.TTFB || r.Timing.Connect <= 0 {
+		t.Fatalf("/slow: timing %+v", *r.Timing)
+	}
+	if r.ErrorKind != ErrorKindSlow || len(r.Warnings) != 1 || !strings.HasPrefix(r.Warnings[0], "slow response") {
+		t.Fatalf("/slow: kind=%q warnings=%v", r.ErrorKind, r.Warnings)
+	}
+
+	r = checkHTTP(context.Background(), client, cfg, srv.URL+"/fast")
+	if !r.OK || len(r.Warnings) != 0 {
+		t.Fatalf("/fast: ok=%v warnings=%v", r.OK, r.Warnings)
+	}
+}
+
+func TestCheckURLs_RateLimitWaitIsNotSlow(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	var urls []string
+	for i := 0; i < 5; i++ {
+		urls = append(urls, fmt.Sprintf("%s/%d", srv.URL, i))
+	}
+	cfg := Config{
+		MaxConcurrency: 5,
+		RequestTimeout: 5 * time.Second,
+		SlowThreshold:  300 * time.Millisecond,
+		Hosts:          []HostConfig{{Match: "127.0.0.1", RPS: 5}},
+	}
+	out := make(chan Result, len(urls))
+	start := time.Now()
+	go CheckURLs(context.Background(), urls, nil, out, nil, cfg)
+	for r := range out {
+		if !r.OK || len(r.Warnings) != 0 || r.ErrorKind != "" {
+			t.Fatalf("%s: ok=%v kind=%q warnings=%v", r.URL, r.OK, r.ErrorKind, r.Warnings)
+		}
+		if r.Timing == nil || r.Timing.Total >= cfg.SlowThreshold {
+			t.Fatalf("%s: timing %+v includes the rate limit wait", r.URL, r.Timing)
+		}
+	}
+	// The limiter did hold the later requests back
+	if elapsed := time.Since(start); elapsed < 600*time.Millisecond {
+		t.Fatalf("5 requests at 5 rps finished in %v", elapsed)
+	}
+}
+
+func TestTiming_MarshalJSON(t *testing.T) {
+	b, err := json.Marshal(Timing{DNS: 1500 * time.Microsecond, Total: 2 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"dnsMs":1.5,"connectMs":0,"tlsMs":0,"ttfbMs":0,"totalMs":2000}`
+	if string(b) != want {
+		t.Fatalf("got %s, want %s", b, want)
+	}
+}
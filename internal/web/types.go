@@ -1,18 +1,65 @@
 package web
 
-import "time"
+import (
+	"time"
+
+	"slinky/internal/fsurls"
+)
+
+// Severity grades a result: ok, warn (works but should be fixed) or error.
+type Severity string
+
+const (
+	SeverityOK    Severity = "ok"
+	SeverityWarn  Severity = "warn"
+	SeverityError Severity = "error"
+)
 
 type Result struct {
-	URL         string
-	OK          bool
-	Status      int
-	Err         error
-	ErrMsg      string
-	Depth       int
-	CacheHit    bool
+	URL          string
+	RewrittenURL string
+	OK           bool
+	Status       int
+	Err          error
+	ErrMsg       string
+	Depth        int
+	CacheHit     bool
+	Skipped      bool
+	Attempts     int
+	StatusRule   string
+	Redirects    []RedirectHop
+	// ErrorKind classifies the failure or warning, when known.
+	ErrorKind ErrorKind
+	// Warnings are non-fatal findings, such as permanent redirects.
+	Warnings []string
+	// SuggestedURL is where the link should point instead, if known.
+	SuggestedURL string
+	// Severity is error for failures, warn for passing links with warnings
+	// and ok otherwise, including skipped links.
+	Severity    Severity
 	Method      string
 	ContentType string
-	Sources     []string
+	// Timing is set for HTTP checks that made a request.
+	Timing  *Timing
+	Sources []fsurls.Source
+
+	// Cache validators from the response, and whether a revalidation
+	// request came back 304 Not Modified.
+	etag, lastModified string
+	notModified        bool
+}
+
+// severityOf grades res from its outcome and warnings.
+func severityOf(res Result) Severity {
+	switch {
+	case res.Skipped:
+		return SeverityOK
+	case !res.OK || res.Err != nil:
+		return SeverityError
+	case len(res.Warnings) > 0:
+		return SeverityWarn
+	}
+	return SeverityOK
 }
 
 type Stats struct {
@@ -24,6 +71,56 @@ type Config struct {
 	MaxDepth       int
 	MaxConcurrency int
 	RequestTimeout time.Duration
-	MaxRetries429  int
+	// ConnectTimeout, TLSTimeout and HeaderTimeout bound the phases of each
+	// request (defaults 2s, 5s and RequestTimeout); RequestTimeout bounds the
+	// whole request.
+	ConnectTimeout time.Duration
+	TLSTimeout     time.Duration
+	HeaderTimeout  time.Duration
+	// SlowThreshold, when set, warns about links slower than it.
+	SlowThreshold time.Duration
+	// MaxRetries is how many times transient failures (timeouts, resets, 408,
+	// 429, 502-504) are retried. Delays grow exponentially from RetryBaseDelay
+	// with jitter unless the server sends Retry-After or X-RateLimit-Reset;
+	// waits longer than RetryMaxDelay end the retries.
+	MaxRetries     int
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
 	Exclude        []string
+	Rewrites       []RewriteRule
+	// CheckMX makes mailto: checks verify recipient domains via DNS.
+	CheckMX bool
+	// DNSServer (host:port) overrides the system resolver for those lookups.
+	DNSServer string
+	// Hosts holds per-host settings, matched by hostname glob.
+	Hosts []HostConfig
+	// StatusPolicies decide which HTTP statuses pass, before the default
+	// (200-399, 401 and 403 accepted).
+	StatusPolicies []StatusPolicy
+	// Soft404 enables heuristics for missing pages served with 200.
+	Soft404 Soft404Config
+	// GitHub resolves github.com links through the REST API.
+	GitHub GitHubConfig
+	// SelfRepo maps links to the scanned repository onto the local checkout.
+	SelfRepo SelfRepoConfig
+	// Cache, when set, reuses results across runs. Passes are reused for
+	// CacheTTL and failures for CacheFailTTL before being rechecked.
+	Cache        *Cache
+	CacheTTL     time.Duration
+	CacheFailTTL time.Duration
+	// Auth holds credentials for matching hosts; the first match is used.
+	Auth []HostAuth
+	// TLS adds CA roots, client certificates and per-host verification.
+	TLS TLSConfig
+	// HTTPSUpgrade checks whether working http:// links also work over
+	// HTTPS and suggests the upgrade.
+	HTTPSUpgrade bool
+	// GlobalRPS caps requests per second across all hosts.
+	GlobalRPS float64
+	// MaxRedirects bounds redirect chains (default 10).
+	MaxRedirects int
+	// MaxBodyBytes bounds how much of a GET response is read (default 64 KiB).
+	MaxBodyBytes int64
+	// Schemes adds or replaces checkers by URL scheme (without ':').
+	Schemes map[string]SchemeChecker
 }
@@ -0,0 +1,77 @@
+package web
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net/url"
+	"strings"
+)
+
+// httpsEquivalent returns raw with its scheme switched to https, or "" when
+// raw is not a plain http:// URL on the default port.
+func httpsEquivalent(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || !strings.EqualFold(u.Scheme, "http") || u.Host == "" {
+		return ""
+	}
+	if p := u.Port(); p != "" && p != "80" {
+		return ""
+	}
+	u.Scheme = "https"
+	u.Host = u.Hostname()
+	if strings.Contains(u.Host, ":") {
+		u.Host = "[" + u.Host + "]"
+	}
+	return u.String()
+}
+
+// finalURL is where a fetch ended up after following its redirects.
+func finalURL(raw string, hops []RedirectHop) string {
+	if len(hops) == 0 {
+		return raw
+	}
+	return hops[len(hops)-1].Location
+}
+
+// httpsUpgrade checks whether the https:// form of a working http:// link
+// serves the same final resource, and returns a warning and the upgraded URL
+// if it does. Links that already redirect to HTTPS are left to the redirect
+// findings.
+func (h *httpChecker) httpsUpgrade(ctx context.Context, raw, method string, fr fetchResult) (warning, suggested string) {
+	target := httpsEquivalent(raw)
+	if target == "" || urlScheme(finalURL(raw, fr.Redirects)) == "https" {
+		return "", ""
+	}
+	sfr, err := fetch(ctx, h.client, method, target, h.cfg.MaxBodyBytes, h.cfg.MaxRedirects)
+	if err != nil {
+		return "", ""
+	}
+	if ok, _ := evaluateStatus(h.cfg.StatusPolicies, target, sfr.Status); !ok || sfr.Status/100 != fr.Status/100 {
+		return "", ""
+	}
+	// The HTTPS chain must land on the same resource as the HTTP one
+	want := CanonicalURL(httpsEquivalent(stripFragment(finalURL(raw, fr.Redirects))))
+	if want == "" || CanonicalURL(stripFragment(finalURL(target, sfr.Redirects))) != want {
+		return "", ""
+	}
+	if a, b := mediaType(fr.Header.Get("Content-Type")), mediaType(sfr.Header.Get("Content-Type")); a != "" && b != "" && a != b {
+		return "", ""
+	}
+	return fmt.Sprintf("HTTPS available; update link to %s", target), target
+}
+
+func stripFragment(raw string) string {
+	s, _, _ := strings.Cut(raw, "#")
+	return s
+}
+
+// mediaType returns the lowercased media type of a Content-Type header
+// without parameters.
+func mediaType(contentType string) string {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	}
+	return mt
+}
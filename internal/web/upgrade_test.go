@@ -0,0 +1,72 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// handlerTransport serves requests from handler without a network, so http
+// and https URLs on the default ports can share one fake host.
+type handlerTransport struct {
+	handler http.Handler
+}
+
+func (t handlerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rec := httptest.NewRecorder()
+	t.handler.ServeHTTP(rec, req)
+	resp := rec.Result()
+	resp.Request = req
+	return resp, nil
+}
+
+func TestHTTPSUpgrade(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		secure := r.URL.Scheme == "https"
+		switch r.URL.Path {
+		case "/same":
+			w.Header().Set("Content-Type", "text/html")
+		case "/elsewhere":
+			if secure {
+				http.Redirect(w, r, "https://example.test/", http.StatusFound)
+			}
+		case "/insecure-only":
+			if secure {
+				w.WriteHeader(http.StatusNotFound)
+			}
+		case "/redirects":
+			if !secure {
+				http.Redirect(w, r, "https://example.test/redirects", http.StatusMovedPermanently)
+			}
+		}
+	})
+	client := &http.Client{Transport: handlerTransport{handler}}
+	cfg := Config{HTTPSUpgrade: true}
+
+	cases := []struct {
+		url       string
+		suggested string
+	}{
+		{"http://example.test/same#intro", "https://example.test/same#intro"},
+		{"http://example.test/elsewhere", ""},
+		{"http://example.test/insecure-only", ""},
+		{"http://example.test:8080/same", ""},
+		{"https://example.test/same", ""},
+	}
+	for _, c := range cases {
+		r := checkHTTP(context.Background(), client, cfg, c.url)
+		if !r.OK || r.SuggestedURL != c.suggested {
+			t.Errorf("%s: ok=%v suggested=%q, want %q", c.url, r.OK, r.SuggestedURL, c.suggested)
+		}
+		if c.suggested != "" && (r.ErrorKind != ErrorKindHTTPSUpgrade || len(r.Warnings) != 1) {
+			t.Errorf("%s: kind=%q warnings=%v", c.url, r.ErrorKind, r.Warnings)
+		}
+	}
+
+	// A link that already redirects to HTTPS gets the redirect suggestion
+	r := checkHTTP(context.Background(), client, cfg, "http://example.test/redirects")
+	if r.SuggestedURL != "https://example.test/redirects" || r.ErrorKind == ErrorKindHTTPSUpgrade {
+		t.Errorf("redirecting link: suggested=%q kind=%q", r.SuggestedURL, r.ErrorKind)
+	}
+}